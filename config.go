@@ -1,27 +1,36 @@
-// Package config provides three ways to set global variables.
-// 1. From system environment
-// 2. From json file
-// 3. From default value(hard code)
-// And in strict ORDER.
+// Package config provides several ways to set global variables, merged in
+// strict ORDER (lowest to highest precedence):
+//  1. Default value(hard code)
+//  2. Config files (json, yaml, toml), in the order given
+//  3. .env files, in the order given
+//  4. Remote Provider (etcd/Consul KV, see SetRemoteProvider), for fields
+//     tagged `remote`
+//  5. System environment
+//  6. Explicit overrides
+//
+// Parse keeps the original single-JSON-file behavior; use ParseWithOptions
+// for the full source chain (see Options).
 //
 // When define a config structure, use tag 'json', 'env' to specify the keys, 'def' to set default value.
 // The tag value '-' will omit the item
 //
-// Only 'all int', 'bool', 'string' fully supported.
-// 支持的格式：各种int、bool和string
+// All int/uint/float kinds, bool, string, time.Duration, time.Time (RFC3339),
+// slices and maps (split on the 'sep'/'kvsep' tags, default "," and "=") are
+// supported, as well as any field implementing Decoder or
+// encoding.TextUnmarshaler. Nested structs (and pointers to structs) recurse,
+// building a dotted env prefix from each level's own `env` tag.
+// 支持的格式：各种int/uint/float、bool、string、time.Duration、time.Time，以及
+// slice、map和嵌套struct。
 //
 // [NOTICE] String should be ALWAYS provided DEFAULT value!!!
 // [高能预警] String类型最好每个字段都设置默认值。
-//
 package config
 
 import (
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
-	"os"
 	"reflect"
-	"strconv"
 )
 
 // SharedLeaf definition
@@ -54,108 +63,45 @@ type SharedBeego struct {
 }
 
 // Parse 来源优先级: environment > json > default
-func Parse(filepath string, st interface{}) {
+//
+// Parse returns a *MultiError collecting every required/validate tag
+// violation found in st once all sources have been applied, so a
+// misconfigured service fails fast at startup with a complete report
+// instead of zero-valuing a field and panicking mid-request later.
+func Parse(filepath string, st interface{}) error {
+	rec := newRecorder(reflect.ValueOf(st).Elem())
+
 	// From json
 	data, err := ioutil.ReadFile(filepath)
 	if err != nil {
-		fmt.Println(err)
+		return err
 	}
-	err = json.Unmarshal(data, &st)
-	if err != nil {
-		fmt.Println(err)
+	if err := json.Unmarshal(data, st); err != nil {
+		return err
 	}
+	rec.mark(reflect.ValueOf(st).Elem(), "json")
 
-	setFromEnvOrDefault(st)
-}
-
-func setFromEnvOrDefault(st interface{}) {
-	// From environment or set as default
-	values := reflect.ValueOf(st).Elem()
-	types := values.Type()
-	fieldNum := types.NumField()
-
-	for i := 0; i < fieldNum; i++ {
-		t := types.Field(i)
-		v := values.Field(i)
-
-		if v.CanSet() == false {
-			panic(fmt.Sprintf("[Config Error]%s Field %s Cannot set.", types.Name(), t.Name))
-		}
-
-		if t.Name == "SharedLeaf" {
-			x := v.Interface().(SharedLeaf)
-			setFromEnvOrDefault(&x)
-			v.Set(reflect.ValueOf(x))
-			continue
-		} else if t.Name == "SharedBeego" {
-			x := v.Interface().(SharedBeego)
-			setFromEnvOrDefault(&x)
-			v.Set(reflect.ValueOf(x))
-			continue
-		}
-
-		// Set from environment
-		if envKey := t.Tag.Get("env"); envKey != "" && envKey != "-" {
-			if envVal := os.Getenv(envKey); envVal != "" {
-				setValue(&v, envVal)
-			}
-		}
-
-		// 如果设置了环境变量，或已经有值，忽略默认值
-		if isSet(&v) {
-			continue
-		}
-
-		// Set as default
-		if def := t.Tag.Get("def"); def != "" && def != "-" {
-			setValue(&v, def)
-		}
+	if err := setFromEnvOrDefaultPrefixedTracked(st, "", rec); err != nil {
+		fmt.Println(err)
 	}
+	registerOrigins(st, rec.origins)
+
+	return validateStruct(st, rec)
 }
 
-// 检查field是否有值
-// 约定空字符串("")、0和false为未设置初始值
-func isSet(field *reflect.Value) bool {
-	switch field.Kind() {
-	case reflect.String:
-		return len(field.Interface().(string)) > 0
-	case reflect.Int, reflect.Int8, reflect.Int32, reflect.Int64:
-		return field.Int() > 0
-	case reflect.Uint, reflect.Uint8, reflect.Uint32, reflect.Uint64:
-		return field.Uint() > 0
-	case reflect.Bool:
-		return field.Interface().(bool)
+// MustParse is Parse, panicking instead of returning an error.
+func MustParse(filepath string, st interface{}) {
+	if err := Parse(filepath, st); err != nil {
+		panic(err)
 	}
-
-	return true
 }
 
-// 将strVal转换成对应的类型并赋值
-func setValue(field *reflect.Value, strVal string) {
-	t := field.Type()
-	switch field.Kind() {
-	case reflect.String:
-		field.SetString(strVal)
-	case reflect.Int, reflect.Int8, reflect.Int32, reflect.Int64:
-		intVal, err := strconv.ParseInt(strVal, 10, 64)
-		if err != nil {
-			fmt.Printf("[Config Error]Invalid value: %s(%T), got %v\n", t.Name(), field.Interface(), strVal)
-		} else {
-			field.SetInt(intVal)
-		}
-	case reflect.Uint, reflect.Uint8, reflect.Uint32, reflect.Uint64:
-		intVal, err := strconv.ParseUint(strVal, 10, 64)
-		if err != nil {
-			fmt.Printf("[Config Error]Invalid value: %s(%T), got %v\n", t.Name(), field.Interface(), strVal)
-		} else {
-			field.SetUint(intVal)
-		}
-	case reflect.Bool:
-		boolVal, err := strconv.ParseBool(strVal)
-		if err != nil {
-			fmt.Printf("[Config Error]Invalid value: %s(%T), got %v\n", t.Name(), field.Interface(), strVal)
-		} else {
-			field.SetBool(boolVal)
-		}
-	}
+// ParseMulti is a convenience wrapper around ParseWithOptions for the common
+// case of layering several config files (json/yaml/toml, picked by
+// extension) and a .env file on top of defaults, env and overrides.
+func ParseMulti(st interface{}, files []string, dotEnvFiles []string) error {
+	return ParseWithOptions(st, Options{
+		Files:       files,
+		DotEnvFiles: dotEnvFiles,
+	})
 }
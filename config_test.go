@@ -0,0 +1,184 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+type configTestLeaf struct {
+	Name string `json:"Name" env:"NAME" def:"fallback"`
+	Port int    `json:"Port" env:"PORT" def:"0"`
+}
+
+func writeConfigTestFile(t *testing.T, name, contents string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(contents), 0o644); err != nil {
+		t.Fatalf("os.WriteFile: %v", err)
+	}
+	return path
+}
+
+// TestParse_JSONThenEnv covers Parse's documented env > json > default
+// precedence: a field set by both the JSON file and the process env must
+// end up with the env value.
+func TestParse_JSONThenEnv(t *testing.T) {
+	path := writeConfigTestFile(t, "config.json", `{"Name":"from-json","Port":8080}`)
+
+	t.Setenv("NAME", "from-env")
+
+	var cfg configTestLeaf
+	if err := Parse(path, &cfg); err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+
+	if cfg.Name != "from-env" {
+		t.Errorf("Name = %q, want %q", cfg.Name, "from-env")
+	}
+	if cfg.Port != 8080 {
+		t.Errorf("Port = %d, want %d", cfg.Port, 8080)
+	}
+}
+
+// TestParseWithOptions_FullPrecedenceChain exercises the whole chain
+// documented on Options: default -> Files -> DotEnvFiles -> process env ->
+// Overrides. Each layer wins on its own field over the values earlier layers
+// wrote there, so every step is observably exercised.
+func TestParseWithOptions_FullPrecedenceChain(t *testing.T) {
+	type cfgT struct {
+		FromDefault  string `json:"FromDefault"  env:"FROM_DEFAULT"  def:"def-value"`
+		FromFile     string `json:"FromFile"     env:"FROM_FILE"`
+		FromDotEnv   string `json:"FromDotEnv"   env:"FROM_DOTENV"`
+		FromEnv      string `json:"FromEnv"      env:"FROM_ENV"`
+		FromOverride string `json:"FromOverride" env:"FROM_OVERRIDE"`
+	}
+
+	filePath := writeConfigTestFile(t, "config.json", `{"FromFile":"file-value","FromDotEnv":"should-be-overridden","FromEnv":"should-be-overridden","FromOverride":"should-be-overridden"}`)
+	dotEnvPath := writeConfigTestFile(t, ".env", "FROM_DOTENV=dotenv-value\nFROM_ENV=should-be-overridden\nFROM_OVERRIDE=should-be-overridden\n")
+
+	t.Setenv("FROM_ENV", "env-value")
+	t.Setenv("FROM_OVERRIDE", "should-be-overridden")
+
+	var cfg cfgT
+	err := ParseWithOptions(&cfg, Options{
+		Files:       []string{filePath},
+		DotEnvFiles: []string{dotEnvPath},
+		Overrides:   map[string]string{"FROM_OVERRIDE": "override-value"},
+	})
+	if err != nil {
+		t.Fatalf("ParseWithOptions: %v", err)
+	}
+
+	want := cfgT{
+		FromDefault:  "def-value",
+		FromFile:     "file-value",
+		FromDotEnv:   "dotenv-value",
+		FromEnv:      "env-value",
+		FromOverride: "override-value",
+	}
+	if cfg != want {
+		t.Errorf("cfg = %+v, want %+v", cfg, want)
+	}
+}
+
+// TestParseWithOptions_EnvPrefix checks that EnvPrefix is applied to the
+// process env lookup.
+func TestParseWithOptions_EnvPrefix(t *testing.T) {
+	type cfgT struct {
+		Host string `env:"HOST"`
+	}
+
+	t.Setenv("APP_HOST", "prefixed-host")
+
+	var cfg cfgT
+	if err := ParseWithOptions(&cfg, Options{EnvPrefix: "APP_"}); err != nil {
+		t.Fatalf("ParseWithOptions: %v", err)
+	}
+
+	if cfg.Host != "prefixed-host" {
+		t.Errorf("Host = %q, want %q", cfg.Host, "prefixed-host")
+	}
+}
+
+// TestParseMulti_LayersFilesAndDotEnv checks the convenience wrapper wires
+// Files and DotEnvFiles through to ParseWithOptions in the right order.
+func TestParseMulti_LayersFilesAndDotEnv(t *testing.T) {
+	type cfgT struct {
+		Name string `json:"Name" env:"NAME"`
+	}
+
+	filePath := writeConfigTestFile(t, "config.json", `{"Name":"file-value"}`)
+	dotEnvPath := writeConfigTestFile(t, ".env", "NAME=dotenv-value\n")
+
+	var cfg cfgT
+	if err := ParseMulti(&cfg, []string{filePath}, []string{dotEnvPath}); err != nil {
+		t.Fatalf("ParseMulti: %v", err)
+	}
+
+	if cfg.Name != "dotenv-value" {
+		t.Errorf("Name = %q, want %q", cfg.Name, "dotenv-value")
+	}
+}
+
+// TestMustParse_PanicsOnError checks the panicking wrapper surfaces Parse's
+// error instead of swallowing it.
+func TestMustParse_PanicsOnError(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatal("MustParse did not panic on a missing file")
+		}
+	}()
+
+	var cfg configTestLeaf
+	MustParse(filepath.Join(t.TempDir(), "missing.json"), &cfg)
+}
+
+// TestParseDotEnv_QuotesExportAndComments covers the dotenv quirks called
+// out in chunk0-1: export-prefixed keys, quoted values and comment/blank
+// lines are all skipped or unwrapped correctly.
+func TestParseDotEnv_QuotesExportAndComments(t *testing.T) {
+	r := strings.NewReader(`
+# a comment
+export NAME="quoted value"
+PORT='8080'
+BARE=unquoted
+
+RAW = spaced
+`)
+
+	m, err := parseDotEnv(r)
+	if err != nil {
+		t.Fatalf("parseDotEnv: %v", err)
+	}
+
+	want := map[string]string{
+		"NAME": "quoted value",
+		"PORT": "8080",
+		"BARE": "unquoted",
+		"RAW":  "spaced",
+	}
+	for k, v := range want {
+		if m[k] != v {
+			t.Errorf("m[%q] = %q, want %q", k, m[k], v)
+		}
+	}
+	if len(m) != len(want) {
+		t.Errorf("parseDotEnv returned %d keys, want %d: %v", len(m), len(want), m)
+	}
+}
+
+// TestLoadDotEnv_ReadsFile checks the file-reading wrapper around
+// parseDotEnv.
+func TestLoadDotEnv_ReadsFile(t *testing.T) {
+	path := writeConfigTestFile(t, ".env", "NAME=from-file\n")
+
+	m, err := loadDotEnv(path)
+	if err != nil {
+		t.Fatalf("loadDotEnv: %v", err)
+	}
+	if m["NAME"] != "from-file" {
+		t.Errorf(`m["NAME"] = %q, want %q`, m["NAME"], "from-file")
+	}
+}
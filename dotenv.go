@@ -0,0 +1,52 @@
+package config
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// dotEnvLine matches a (possibly `export`-prefixed) KEY=VALUE pair, ignoring
+// surrounding whitespace around the key and the `=`.
+var dotEnvLine = regexp.MustCompile(`^\s*(?:export\s+)?([A-Za-z_][A-Za-z0-9_]*)\s*=\s*(.*?)\s*$`)
+
+// loadDotEnv reads a .env file into a key/value map. Blank lines and lines
+// starting with `#` are skipped. Values may be wrapped in single or double
+// quotes, in which case the quotes are stripped.
+func loadDotEnv(filepath string) (map[string]string, error) {
+	f, err := os.Open(filepath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return parseDotEnv(f)
+}
+
+func parseDotEnv(r io.Reader) (map[string]string, error) {
+	out := make(map[string]string)
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		m := dotEnvLine.FindStringSubmatch(line)
+		if m == nil {
+			return nil, fmt.Errorf("dotenv: cannot parse line %q", line)
+		}
+
+		key, val := m[1], m[2]
+		if len(val) >= 2 && (val[0] == '"' && val[len(val)-1] == '"' || val[0] == '\'' && val[len(val)-1] == '\'') {
+			val = val[1 : len(val)-1]
+		}
+		out[key] = val
+	}
+
+	return out, scanner.Err()
+}
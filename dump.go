@@ -0,0 +1,173 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// dumpLeaf is one leaf field collected by walkDump, carrying both the
+// json-tag path (nested dump formats) and the env-style key (flat dump
+// formats) it's addressed by.
+type dumpLeaf struct {
+	jsonPath []string
+	envKey   string
+	value    reflect.Value
+	secret   bool
+}
+
+// Dump writes the fully-resolved configuration in st to w. format is one
+// of "json", "yaml" or "env" ("KEY=VALUE" lines, one per field, in the
+// spirit of a .env file). Fields tagged `secret:"true"` are always
+// replaced with "***", regardless of format — unlike Explain, Dump has no
+// opt-out, since it is meant to be safe to paste into a bug report.
+func Dump(st interface{}, w io.Writer, format string) error {
+	leaves := walkDump(reflect.ValueOf(st).Elem(), nil, "")
+
+	switch strings.ToLower(format) {
+	case "json":
+		return dumpJSON(leaves, w)
+	case "yaml", "yml":
+		return dumpFlat(leaves, w, ": ")
+	case "env":
+		return dumpEnv(leaves, w)
+	default:
+		return fmt.Errorf("config: Dump: unsupported format %q", format)
+	}
+}
+
+func walkDump(values reflect.Value, jsonPrefix []string, envPrefix string) []dumpLeaf {
+	var out []dumpLeaf
+
+	types := values.Type()
+	for i := 0; i < types.NumField(); i++ {
+		t := types.Field(i)
+		v := values.Field(i)
+
+		jsonKey := t.Tag.Get("json")
+		if jsonKey == "" || jsonKey == "-" {
+			jsonKey = t.Name
+		}
+		jsonPath := append(append([]string{}, jsonPrefix...), jsonKey)
+
+		if isNestedStruct(v) {
+			envChildPrefix := childEnvPrefix(t, envPrefix)
+			if v.Kind() == reflect.Ptr {
+				if v.IsNil() {
+					continue
+				}
+				out = append(out, walkDump(v.Elem(), jsonPath, envChildPrefix)...)
+			} else {
+				out = append(out, walkDump(v, jsonPath, envChildPrefix)...)
+			}
+			continue
+		}
+
+		envKey := t.Tag.Get("env")
+		if envKey == "-" {
+			envKey = ""
+		} else if envKey == "" {
+			envKey = strings.ToUpper(t.Name)
+		}
+		if envKey != "" && envPrefix != "" {
+			envKey = envPrefix + "_" + envKey
+		}
+
+		out = append(out, dumpLeaf{
+			jsonPath: jsonPath,
+			envKey:   envKey,
+			value:    v,
+			secret:   t.Tag.Get("secret") == "true",
+		})
+	}
+
+	return out
+}
+
+func (l dumpLeaf) display() interface{} {
+	if l.secret {
+		return "***"
+	}
+	return valueToInterface(l.value)
+}
+
+// valueToInterface converts a leaf reflect.Value into something
+// encoding/json can marshal natively (durations and times as their string
+// form, everything else via Interface()).
+func valueToInterface(v reflect.Value) interface{} {
+	switch v.Type() {
+	case durationType, timeType:
+		return formatScalarValue(v)
+	}
+
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return nil
+		}
+		return valueToInterface(v.Elem())
+	}
+
+	return v.Interface()
+}
+
+func dumpJSON(leaves []dumpLeaf, w io.Writer) error {
+	tree := make(map[string]interface{})
+	for _, leaf := range leaves {
+		setTreePath(tree, leaf.jsonPath, leaf.display())
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(tree)
+}
+
+func setTreePath(tree map[string]interface{}, path []string, value interface{}) {
+	for _, seg := range path[:len(path)-1] {
+		next, ok := tree[seg].(map[string]interface{})
+		if !ok {
+			next = make(map[string]interface{})
+			tree[seg] = next
+		}
+		tree = next
+	}
+	tree[path[len(path)-1]] = value
+}
+
+// dumpFlat renders every leaf as "key<sep>value", one per line, sorted by
+// key for a stable diff. Used for the YAML format: nested fields are written
+// as dotted keys ("DB.Host: ..."), not as indented blocks.
+func dumpFlat(leaves []dumpLeaf, w io.Writer, sep string) error {
+	lines := make([]string, len(leaves))
+	for i, leaf := range leaves {
+		lines[i] = strings.Join(leaf.jsonPath, ".") + sep + fmt.Sprintf("%v", leaf.display())
+	}
+	sort.Strings(lines)
+
+	for _, line := range lines {
+		if _, err := fmt.Fprintln(w, line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func dumpEnv(leaves []dumpLeaf, w io.Writer) error {
+	lines := make([]string, 0, len(leaves))
+	for _, leaf := range leaves {
+		if leaf.envKey == "" {
+			continue
+		}
+		lines = append(lines, leaf.envKey+"="+fmt.Sprintf("%v", leaf.display()))
+	}
+	sort.Strings(lines)
+
+	for _, line := range lines {
+		if _, err := fmt.Fprintln(w, line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,109 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+type dumpTestDB struct {
+	Host string `json:"Host" env:"HOST"`
+}
+
+type dumpTestConfig struct {
+	Name     string     `json:"Name"     env:"NAME"`
+	Password string     `json:"Password" env:"PASSWORD" secret:"true"`
+	DB       dumpTestDB `json:"DB"`
+}
+
+func dumpTestData() dumpTestConfig {
+	return dumpTestConfig{
+		Name:     "svc",
+		Password: "s3cr3t",
+		DB:       dumpTestDB{Host: "db-host"},
+	}
+}
+
+func TestDump_JSON(t *testing.T) {
+	cfg := dumpTestData()
+
+	var buf bytes.Buffer
+	if err := Dump(&cfg, &buf, "json"); err != nil {
+		t.Fatalf("Dump: %v", err)
+	}
+
+	var got map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("json.Unmarshal(output): %v", err)
+	}
+
+	if got["Name"] != "svc" {
+		t.Errorf(`Name = %v, want "svc"`, got["Name"])
+	}
+	if got["Password"] != "***" {
+		t.Errorf(`Password = %v, want "***" (secret not redacted)`, got["Password"])
+	}
+	db, ok := got["DB"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("DB = %v, want nested object", got["DB"])
+	}
+	if db["Host"] != "db-host" {
+		t.Errorf(`DB.Host = %v, want "db-host"`, db["Host"])
+	}
+}
+
+func TestDump_YAML(t *testing.T) {
+	cfg := dumpTestData()
+
+	var buf bytes.Buffer
+	if err := Dump(&cfg, &buf, "yaml"); err != nil {
+		t.Fatalf("Dump: %v", err)
+	}
+
+	out := buf.String()
+	if !strings.Contains(out, "Name: svc") {
+		t.Errorf("output missing \"Name: svc\":\n%s", out)
+	}
+	if !strings.Contains(out, "Password: ***") {
+		t.Errorf("output missing redacted Password:\n%s", out)
+	}
+	if strings.Contains(out, "s3cr3t") {
+		t.Errorf("output leaks secret value:\n%s", out)
+	}
+	if !strings.Contains(out, "DB.Host: db-host") {
+		t.Errorf("output missing \"DB.Host: db-host\":\n%s", out)
+	}
+}
+
+func TestDump_Env(t *testing.T) {
+	cfg := dumpTestData()
+
+	var buf bytes.Buffer
+	if err := Dump(&cfg, &buf, "env"); err != nil {
+		t.Fatalf("Dump: %v", err)
+	}
+
+	out := buf.String()
+	if !strings.Contains(out, "NAME=svc") {
+		t.Errorf("output missing \"NAME=svc\":\n%s", out)
+	}
+	if !strings.Contains(out, "PASSWORD=***") {
+		t.Errorf("output missing redacted PASSWORD:\n%s", out)
+	}
+	if strings.Contains(out, "s3cr3t") {
+		t.Errorf("output leaks secret value:\n%s", out)
+	}
+	if !strings.Contains(out, "DB_HOST=db-host") {
+		t.Errorf("output missing \"DB_HOST=db-host\":\n%s", out)
+	}
+}
+
+func TestDump_UnsupportedFormat(t *testing.T) {
+	cfg := dumpTestData()
+
+	var buf bytes.Buffer
+	if err := Dump(&cfg, &buf, "xml"); err == nil {
+		t.Fatal("Dump with an unsupported format did not error")
+	}
+}
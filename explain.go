@@ -0,0 +1,173 @@
+package config
+
+import (
+	"reflect"
+	"runtime"
+	"sort"
+	"sync"
+)
+
+// FieldOrigin describes which source last set a field.
+type FieldOrigin struct {
+	Path   string // dotted field path, e.g. "DB.Host"
+	Value  string // the field's current value, stringified
+	Source string // one of "env", "json", "remote", "default", "override"
+}
+
+// recorder tracks, across the successive source-application passes of
+// Parse/ParseWithOptions, which source last changed each leaf field. It
+// works by diffing the struct against a running snapshot after every pass
+// and attributing every changed field to that pass's source.
+//
+// Caveat: a source that explicitly sets a field to its zero value is
+// indistinguishable from a source that never touched it, since both leave
+// the struct unchanged — such a field simply keeps whatever origin (or
+// none) an earlier pass gave it.
+type recorder struct {
+	snapshot reflect.Value
+	origins  map[string]FieldOrigin
+}
+
+func newRecorder(root reflect.Value) *recorder {
+	snap := reflect.New(root.Type()).Elem()
+	snap.Set(root)
+	return &recorder{snapshot: snap, origins: make(map[string]FieldOrigin)}
+}
+
+func (r *recorder) mark(root reflect.Value, source string) {
+	if r == nil {
+		return
+	}
+
+	for _, leaf := range diffLeaves(r.snapshot, root, "") {
+		r.origins[leaf.path] = FieldOrigin{
+			Path:   leaf.path,
+			Value:  formatScalarValue(leaf.value),
+			Source: source,
+		}
+	}
+	r.snapshot.Set(root)
+}
+
+// touched reports whether some source has already recorded an origin for
+// path, i.e. whether the field was actually set rather than just left at
+// its zero value. Nil-safe: an untracked recorder reports nothing touched,
+// so callers fall back to the isSet zero-value heuristic.
+func (r *recorder) touched(path string) bool {
+	if r == nil {
+		return false
+	}
+	_, ok := r.origins[path]
+	return ok
+}
+
+// originRegistry maps a parsed struct's pointer to the origins recorded for
+// it by the Parse/ParseWithOptions call that last populated it. Entries are
+// evicted by the finalizer registerOrigins attaches to st, rather than left
+// to accumulate forever — important for a Watcher, which calls Parse on a
+// freshly allocated struct every reload.
+var originRegistry sync.Map // uintptr -> map[string]FieldOrigin
+
+func registerOrigins(st interface{}, origins map[string]FieldOrigin) {
+	key := reflect.ValueOf(st).Pointer()
+	originRegistry.Store(key, origins)
+
+	// Once st is unreachable, its entry is both dead weight and unsound to
+	// keep around: the allocator can hand the same address to an unrelated
+	// struct, which would then pick up these stale origins on its first
+	// Explain call. Delete it as soon as the GC says st is gone.
+	//
+	// st may already carry a finalizer from an earlier registerOrigins call
+	// (e.g. a Watcher re-registering the same live struct on every Reload);
+	// SetFinalizer panics if one is already attached, so clear it first.
+	runtime.SetFinalizer(st, nil)
+	runtime.SetFinalizer(st, func(interface{}) {
+		originRegistry.Delete(key)
+	})
+}
+
+func loadOrigins(st interface{}) map[string]FieldOrigin {
+	v, ok := originRegistry.Load(reflect.ValueOf(st).Pointer())
+	if !ok {
+		return nil
+	}
+	return v.(map[string]FieldOrigin)
+}
+
+// ExplainOption configures Explain.
+type ExplainOption func(*explainOptions)
+
+type explainOptions struct {
+	includeSecrets bool
+}
+
+// IncludeSecrets makes Explain return the real value of `secret:"true"`
+// fields instead of "***". Dump always redacts secrets regardless.
+func IncludeSecrets() ExplainOption {
+	return func(o *explainOptions) { o.includeSecrets = true }
+}
+
+// Explain reports, for every field of st that a source has set since the
+// last Parse/ParseWithOptions call, which source won and the field's
+// current value. Fields tagged `secret:"true"` are redacted to "***"
+// unless IncludeSecrets is passed. Returns nil if st has never been parsed.
+func Explain(st interface{}, opts ...ExplainOption) []FieldOrigin {
+	cfg := &explainOptions{}
+	for _, o := range opts {
+		o(cfg)
+	}
+
+	origins := loadOrigins(st)
+	if origins == nil {
+		return nil
+	}
+
+	secret := collectSecretPaths(reflect.ValueOf(st).Elem(), "")
+
+	out := make([]FieldOrigin, 0, len(origins))
+	for path, fo := range origins {
+		if secret[path] && !cfg.includeSecrets {
+			fo.Value = "***"
+		}
+		out = append(out, fo)
+	}
+
+	sort.Slice(out, func(i, j int) bool { return out[i].Path < out[j].Path })
+
+	return out
+}
+
+// collectSecretPaths returns the set of dotted field paths tagged
+// `secret:"true"`, using the same path format as diffLeaves/validateStruct.
+func collectSecretPaths(values reflect.Value, path string) map[string]bool {
+	out := make(map[string]bool)
+
+	types := values.Type()
+	for i := 0; i < types.NumField(); i++ {
+		t := types.Field(i)
+		v := values.Field(i)
+		fieldPath := joinPath(path, t.Name)
+
+		if isNestedStruct(v) {
+			if v.Kind() == reflect.Ptr {
+				if v.IsNil() {
+					continue
+				}
+				for p := range collectSecretPaths(v.Elem(), fieldPath) {
+					out[p] = true
+				}
+			} else {
+				for p := range collectSecretPaths(v, fieldPath) {
+					out[p] = true
+				}
+			}
+			continue
+		}
+
+		if t.Tag.Get("secret") == "true" {
+			out[fieldPath] = true
+		}
+	}
+
+	return out
+}
@@ -0,0 +1,43 @@
+package config
+
+import "sync"
+
+// Provider is a remote key/value source for config fields tagged
+// `remote:"some/key/path"`, e.g. an etcd or Consul KV store. Get returns
+// ok=false (not an error) when the key is simply absent. Watch streams the
+// raw string value every time it changes; the channel is closed if the
+// watch can no longer be serviced.
+type Provider interface {
+	Get(key string) (string, bool, error)
+	Watch(key string) (<-chan string, error)
+}
+
+// remoteProvider is the Provider setFromEnvOrDefaultPrefixedTracked consults for `remote`
+// tags, registered via SetRemoteProvider. Nil (the default) means no field
+// has a remote source to pull from, same as before this was introduced.
+// Guarded by remoteProviderMu since a Watcher reloads on its own goroutine
+// indefinitely, so a SetRemoteProvider call racing a Parse/ParseWithOptions
+// in flight is a realistic scenario, not just a concurrent-test artifact.
+var (
+	remoteProviderMu sync.RWMutex
+	remoteProvider   Provider
+)
+
+// SetRemoteProvider registers the Provider consulted for every `remote`
+// tag, sitting between the loaded config file and process env in the
+// precedence chain: a value present in p overrides the file/default but is
+// itself overridden by a real environment variable or Overrides entry, so
+// an operator can flip a key fleet-wide while a single host still retains
+// the ability to override it locally.
+func SetRemoteProvider(p Provider) {
+	remoteProviderMu.Lock()
+	defer remoteProviderMu.Unlock()
+	remoteProvider = p
+}
+
+// getRemoteProvider returns the currently registered Provider, or nil.
+func getRemoteProvider() Provider {
+	remoteProviderMu.RLock()
+	defer remoteProviderMu.RUnlock()
+	return remoteProvider
+}
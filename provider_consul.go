@@ -0,0 +1,75 @@
+package config
+
+import (
+	"time"
+
+	"github.com/hashicorp/consul/api"
+)
+
+// ConsulProvider is a Provider backed by Consul's KV store.
+type ConsulProvider struct {
+	kv *api.KV
+}
+
+// NewConsulProvider connects to the Consul agent at addr ("" for the
+// default http://127.0.0.1:8500) and returns a ready-to-use ConsulProvider.
+func NewConsulProvider(addr string) (*ConsulProvider, error) {
+	cfg := api.DefaultConfig()
+	if addr != "" {
+		cfg.Address = addr
+	}
+
+	cli, err := api.NewClient(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ConsulProvider{kv: cli.KV()}, nil
+}
+
+// Get returns the string value stored at key.
+func (p *ConsulProvider) Get(key string) (string, bool, error) {
+	pair, _, err := p.kv.Get(key, nil)
+	if err != nil {
+		return "", false, err
+	}
+	if pair == nil {
+		return "", false, nil
+	}
+
+	return string(pair.Value), true, nil
+}
+
+// Watch streams key's value every time it changes, using Consul's blocking
+// queries. The first query does not block, so the key's current value (if
+// present) is sent once right away. The returned channel is closed as soon
+// as a query returns an error; failed queries are not retried.
+func (p *ConsulProvider) Watch(key string) (<-chan string, error) {
+	ch := make(chan string)
+
+	go func() {
+		defer close(ch)
+
+		var lastIndex uint64
+		for {
+			pair, meta, err := p.kv.Get(key, &api.QueryOptions{WaitIndex: lastIndex})
+			if err != nil {
+				return
+			}
+			if meta.LastIndex == lastIndex {
+				// A blocking query can return immediately with an
+				// unchanged index (a known Consul gotcha); without a
+				// pause this becomes a tight loop hammering the agent.
+				time.Sleep(time.Second)
+				continue
+			}
+			lastIndex = meta.LastIndex
+
+			if pair != nil {
+				ch <- string(pair.Value)
+			}
+		}
+	}()
+
+	return ch, nil
+}
@@ -0,0 +1,75 @@
+package config
+
+import (
+	"context"
+	"time"
+
+	clientv3 "go.etcd.io/etcd/client/v3"
+)
+
+// EtcdProvider is a Provider backed by etcd v3's key/value store.
+type EtcdProvider struct {
+	cli     *clientv3.Client
+	timeout time.Duration
+}
+
+// NewEtcdProvider dials endpoints and returns a ready-to-use EtcdProvider.
+// timeout is the dial timeout and bounds every individual Get request; 5s is
+// used if timeout is 0. Watch is not bounded by it.
+func NewEtcdProvider(endpoints []string, timeout time.Duration) (*EtcdProvider, error) {
+	if timeout == 0 {
+		timeout = 5 * time.Second
+	}
+
+	cli, err := clientv3.New(clientv3.Config{
+		Endpoints:   endpoints,
+		DialTimeout: timeout,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &EtcdProvider{cli: cli, timeout: timeout}, nil
+}
+
+// Get returns the string value stored at key, the same int/bool/string
+// primitive coercion applying downstream as for any other source.
+func (p *EtcdProvider) Get(key string) (string, bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), p.timeout)
+	defer cancel()
+
+	resp, err := p.cli.Get(ctx, key)
+	if err != nil {
+		return "", false, err
+	}
+	if len(resp.Kvs) == 0 {
+		return "", false, nil
+	}
+
+	return string(resp.Kvs[0].Value), true, nil
+}
+
+// Watch streams key's value every time it changes until the client is
+// closed, at which point the returned channel is closed too.
+func (p *EtcdProvider) Watch(key string) (<-chan string, error) {
+	ch := make(chan string)
+
+	watchCh := p.cli.Watch(context.Background(), key)
+	go func() {
+		defer close(ch)
+		for resp := range watchCh {
+			for _, ev := range resp.Events {
+				if ev.Kv != nil {
+					ch <- string(ev.Kv.Value)
+				}
+			}
+		}
+	}()
+
+	return ch, nil
+}
+
+// Close releases the underlying etcd client connection.
+func (p *EtcdProvider) Close() error {
+	return p.cli.Close()
+}
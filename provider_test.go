@@ -0,0 +1,71 @@
+package config
+
+import "testing"
+
+// stubProvider is a fake, in-memory Provider used to exercise the `remote`
+// tag / SetRemoteProvider plumbing without a live etcd or Consul.
+type stubProvider struct {
+	values map[string]string
+}
+
+func (p *stubProvider) Get(key string) (string, bool, error) {
+	v, ok := p.values[key]
+	return v, ok, nil
+}
+
+func (p *stubProvider) Watch(key string) (<-chan string, error) {
+	ch := make(chan string)
+	close(ch)
+	return ch, nil
+}
+
+// TestApplyRemote_WinsOverDefaultButNotEnv covers the precedence documented
+// on setFromEnvOrDefaultPrefixedTracked: a `remote` value overrides `def`
+// but a real environment variable still overrides it in turn.
+func TestApplyRemote_WinsOverDefaultButNotEnv(t *testing.T) {
+	SetRemoteProvider(&stubProvider{values: map[string]string{
+		"app/host": "remote-host",
+		"app/port": "9000",
+	}})
+	defer SetRemoteProvider(nil)
+
+	t.Setenv("PORT", "6543")
+
+	type st struct {
+		Host string `remote:"app/host" def:"localhost"`
+		Port int    `remote:"app/port" env:"PORT" def:"1"`
+	}
+
+	var v st
+	if err := setFromEnvOrDefaultPrefixedTracked(&v, "", nil); err != nil {
+		t.Fatalf("setFromEnvOrDefaultPrefixedTracked: %v", err)
+	}
+
+	if v.Host != "remote-host" {
+		t.Errorf("Host = %q, want remote value %q", v.Host, "remote-host")
+	}
+	if v.Port != 6543 {
+		t.Errorf("Port = %d, want env value %d to win over remote", v.Port, 6543)
+	}
+}
+
+// TestApplyRemote_MissingKeyFallsThrough checks that a Provider without the
+// requested key behaves like the field was never tagged `remote` at all,
+// leaving it to the default phase.
+func TestApplyRemote_MissingKeyFallsThrough(t *testing.T) {
+	SetRemoteProvider(&stubProvider{values: map[string]string{}})
+	defer SetRemoteProvider(nil)
+
+	type st struct {
+		Host string `remote:"app/host" def:"localhost"`
+	}
+
+	var v st
+	if err := setFromEnvOrDefaultPrefixedTracked(&v, "", nil); err != nil {
+		t.Fatalf("setFromEnvOrDefaultPrefixedTracked: %v", err)
+	}
+
+	if v.Host != "localhost" {
+		t.Errorf("Host = %q, want def fallback %q", v.Host, "localhost")
+	}
+}
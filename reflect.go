@@ -0,0 +1,457 @@
+package config
+
+import (
+	"encoding"
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Decoder lets a field parse itself from a raw string value, taking
+// priority over the built-in kind-based coercions in decodeValue. This is
+// the escape hatch for custom types (IP lists, log levels, URLs, ...).
+type Decoder interface {
+	Decode(string) error
+}
+
+var (
+	durationType        = reflect.TypeOf(time.Duration(0))
+	timeType            = reflect.TypeOf(time.Time{})
+	decoderType         = reflect.TypeOf((*Decoder)(nil)).Elem()
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// setFromEnvOrDefaultPrefixedTracked walks st recursively, applying the
+// remote/env/default sources in precedence order. Every struct or
+// pointer-to-struct field (other than time.Time, or one implementing
+// Decoder/encoding.TextUnmarshaler) is treated as a nested scope: its own
+// `env` tag (default: the upper-cased field name, "-" or "" to not add a
+// segment) is appended to a dotted env prefix, e.g. a `DB` field inside an
+// `App` struct yields APP_DB_HOST for a HOST-tagged child field. The same
+// nesting builds a dotted field path (e.g. "DB.Host"), used to record and
+// later look up which source touched each field.
+//
+// A leaf field tagged `remote:"some/key/path"` is additionally looked up
+// against the registered Provider (see SetRemoteProvider) before its `env`
+// tag, so a real environment variable still wins over a remote value.
+//
+// When rec is non-nil, it both records which source last touched each
+// field for Explain, and lets applyDefault tell an explicitly-set zero or
+// negative value apart from a field no source has touched yet — something
+// the isSet zero-value heuristic alone cannot do. It runs the three sources
+// as separate full-tree passes, in precedence order, diffing against rec's
+// snapshot after each so the origin recorded is always the one that
+// actually won.
+//
+// Returns a *MultiError collecting every field's remote/env/default decode
+// failure (invalid duration, unparsable int, unsupported map entry, ...),
+// or nil if every field decoded cleanly. Callers that don't care (Parse)
+// are free to discard or just print it; ParseWithOptions folds it into its
+// own *MultiError when Options.Strict is set.
+func setFromEnvOrDefaultPrefixedTracked(st interface{}, prefix string, rec *recorder) error {
+	root := reflect.ValueOf(st).Elem()
+	p := strings.TrimSuffix(prefix, "_")
+	errs := &MultiError{}
+
+	errs.Errors = append(errs.Errors, walkStructPhase(root, p, "", phaseRemote, rec).Errors...)
+	rec.mark(root, "remote")
+
+	errs.Errors = append(errs.Errors, walkStructPhase(root, p, "", phaseEnv, rec).Errors...)
+	rec.mark(root, "env")
+
+	errs.Errors = append(errs.Errors, walkStructPhase(root, p, "", phaseDefault, rec).Errors...)
+	rec.mark(root, "default")
+
+	if len(errs.Errors) == 0 {
+		return nil
+	}
+	return errs
+}
+
+type phase int
+
+const (
+	phaseRemote phase = iota
+	phaseEnv
+	phaseDefault
+)
+
+func walkStructPhase(values reflect.Value, prefix, path string, ph phase, rec *recorder) *MultiError {
+	errs := &MultiError{}
+	types := values.Type()
+
+	for i := 0; i < types.NumField(); i++ {
+		t := types.Field(i)
+		v := values.Field(i)
+		fieldPath := joinPath(path, t.Name)
+
+		if !v.CanSet() {
+			panic(fmt.Sprintf("[Config Error]%s Field %s Cannot set.", types.Name(), t.Name))
+		}
+
+		if isNestedStruct(v) {
+			if v.Kind() == reflect.Ptr {
+				if v.IsNil() {
+					v.Set(reflect.New(v.Type().Elem()))
+				}
+				errs.Errors = append(errs.Errors, walkStructPhase(v.Elem(), childEnvPrefix(t, prefix), fieldPath, ph, rec).Errors...)
+			} else {
+				errs.Errors = append(errs.Errors, walkStructPhase(v, childEnvPrefix(t, prefix), fieldPath, ph, rec).Errors...)
+			}
+			continue
+		}
+
+		var err error
+		switch ph {
+		case phaseRemote:
+			err = applyRemote(v, t)
+		case phaseEnv:
+			err = applyEnv(v, t, prefix)
+		case phaseDefault:
+			err = applyDefault(v, t, fieldPath, rec)
+		}
+		if err != nil {
+			errs.add(fmt.Errorf("%s: %w", fieldPath, err))
+		}
+	}
+
+	return errs
+}
+
+// childEnvPrefix computes the dotted env prefix a nested struct field
+// contributes, honoring an overriding `env` tag.
+func childEnvPrefix(t reflect.StructField, prefix string) string {
+	segment, ok := t.Tag.Lookup("env")
+	if !ok {
+		segment = strings.ToUpper(t.Name)
+	}
+	if segment == "" || segment == "-" {
+		return prefix
+	}
+	if prefix == "" {
+		return segment
+	}
+	return prefix + "_" + segment
+}
+
+// isNestedStruct reports whether v should be recursed into rather than
+// decoded as a leaf value.
+func isNestedStruct(v reflect.Value) bool {
+	typ := v.Type()
+	if typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	if typ.Kind() != reflect.Struct || typ == timeType {
+		return false
+	}
+	return !implementsDecoder(v)
+}
+
+func implementsDecoder(v reflect.Value) bool {
+	if v.CanAddr() {
+		pt := v.Addr().Type()
+		if pt.Implements(decoderType) || pt.Implements(textUnmarshalerType) {
+			return true
+		}
+	}
+	return v.Type().Implements(decoderType) || v.Type().Implements(textUnmarshalerType)
+}
+
+// applyRemote sets v from the registered Provider (see SetRemoteProvider)
+// if the field carries a `remote` tag and the provider has that key.
+func applyRemote(v reflect.Value, t reflect.StructField) error {
+	remoteKey := t.Tag.Get("remote")
+	if remoteKey == "" || remoteKey == "-" {
+		return nil
+	}
+	provider := getRemoteProvider()
+	if provider == nil {
+		return nil
+	}
+
+	val, ok, err := provider.Get(remoteKey)
+	if err != nil {
+		return fmt.Errorf("remote Get(%s): %w", remoteKey, err)
+	}
+	if ok && val != "" {
+		return decodeValue(v, t, val)
+	}
+	return nil
+}
+
+// applyEnv sets v from the process environment under prefix+tag (default:
+// the upper-cased field name).
+func applyEnv(v reflect.Value, t reflect.StructField, prefix string) error {
+	envKey := t.Tag.Get("env")
+	if envKey == "-" {
+		envKey = ""
+	} else if envKey == "" {
+		envKey = strings.ToUpper(t.Name)
+	}
+	if envKey == "" {
+		return nil
+	}
+
+	full := envKey
+	if prefix != "" {
+		full = prefix + "_" + envKey
+	}
+	if envVal := os.Getenv(full); envVal != "" {
+		return decodeValue(v, t, envVal)
+	}
+	return nil
+}
+
+// applyDefault sets v from its `def` tag, unless some earlier source already
+// touched it. rec.touched is checked first because it can tell an explicit
+// zero or negative value apart from a field nothing has set yet; isSet is
+// kept as a fallback for untracked callers (rec == nil), where it's the only
+// signal available and a field already holding a non-zero value obviously
+// shouldn't be re-defaulted.
+func applyDefault(v reflect.Value, t reflect.StructField, path string, rec *recorder) error {
+	if rec.touched(path) || isSet(v) {
+		return nil
+	}
+
+	if def := t.Tag.Get("def"); def != "" && def != "-" {
+		return decodeValue(v, t, def)
+	}
+	return nil
+}
+
+// 检查field是否有值
+// 约定零值（空字符串、0、false、nil、空slice/map）为未设置初始值
+func isSet(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.String:
+		return len(v.String()) > 0
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int() > 0
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return v.Uint() > 0
+	case reflect.Float32, reflect.Float64:
+		return v.Float() > 0
+	case reflect.Bool:
+		return v.Bool()
+	case reflect.Slice, reflect.Map:
+		return v.Len() > 0
+	case reflect.Ptr:
+		return !v.IsNil()
+	case reflect.Struct:
+		if v.Type() == timeType {
+			return !v.Interface().(time.Time).IsZero()
+		}
+	}
+
+	return true
+}
+
+// decodeValue converts strVal into field's type and assigns it, in this
+// priority order: Decoder, encoding.TextUnmarshaler, time.Duration,
+// time.Time (RFC3339), then the built-in kind switch (including slice and
+// map, split on the `sep`/`kvsep` tags). Returns an error on any
+// parse/decode failure. A scalar field is then left unchanged; a slice or
+// map field is still assigned, with bad slice elements zero-valued and bad
+// map entries left out. Callers decide whether that's fatal
+// (ParseWithOptions with Options.Strict) or just worth logging.
+func decodeValue(field reflect.Value, t reflect.StructField, strVal string) error {
+	if field.CanAddr() {
+		addr := field.Addr()
+		if dec, ok := addr.Interface().(Decoder); ok {
+			if err := dec.Decode(strVal); err != nil {
+				return fmt.Errorf("%s.Decode(%q): %w", t.Name, strVal, err)
+			}
+			return nil
+		}
+		if tu, ok := addr.Interface().(encoding.TextUnmarshaler); ok {
+			if err := tu.UnmarshalText([]byte(strVal)); err != nil {
+				return fmt.Errorf("%s.UnmarshalText(%q): %w", t.Name, strVal, err)
+			}
+			return nil
+		}
+	}
+
+	if field.Type() == durationType {
+		d, err := time.ParseDuration(strVal)
+		if err != nil {
+			return fmt.Errorf("invalid duration for %s: %q: %w", t.Name, strVal, err)
+		}
+		field.SetInt(int64(d))
+		return nil
+	}
+
+	if field.Type() == timeType {
+		tm, err := time.Parse(time.RFC3339, strVal)
+		if err != nil {
+			return fmt.Errorf("invalid time (want RFC3339) for %s: %q: %w", t.Name, strVal, err)
+		}
+		field.Set(reflect.ValueOf(tm))
+		return nil
+	}
+
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(strVal)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		intVal, err := strconv.ParseInt(strVal, 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid value for %s (%s): %q: %w", t.Name, field.Type(), strVal, err)
+		}
+		field.SetInt(intVal)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		uintVal, err := strconv.ParseUint(strVal, 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid value for %s (%s): %q: %w", t.Name, field.Type(), strVal, err)
+		}
+		field.SetUint(uintVal)
+	case reflect.Float32, reflect.Float64:
+		floatVal, err := strconv.ParseFloat(strVal, 64)
+		if err != nil {
+			return fmt.Errorf("invalid value for %s (%s): %q: %w", t.Name, field.Type(), strVal, err)
+		}
+		field.SetFloat(floatVal)
+	case reflect.Bool:
+		boolVal, err := strconv.ParseBool(strVal)
+		if err != nil {
+			return fmt.Errorf("invalid value for %s (%s): %q: %w", t.Name, field.Type(), strVal, err)
+		}
+		field.SetBool(boolVal)
+	case reflect.Slice:
+		return decodeSlice(field, t, strVal)
+	case reflect.Map:
+		return decodeMap(field, t, strVal)
+	}
+	return nil
+}
+
+func tagOrDefault(t reflect.StructField, key, def string) string {
+	if v := t.Tag.Get(key); v != "" {
+		return v
+	}
+	return def
+}
+
+func decodeSlice(field reflect.Value, t reflect.StructField, strVal string) error {
+	sep := tagOrDefault(t, "sep", ",")
+	parts := strings.Split(strVal, sep)
+
+	elemType := field.Type().Elem()
+	out := reflect.MakeSlice(field.Type(), len(parts), len(parts))
+	errs := &MultiError{}
+	for i, part := range parts {
+		elem := reflect.New(elemType).Elem()
+		if err := decodeScalar(elem, t, strings.TrimSpace(part)); err != nil {
+			errs.add(fmt.Errorf("%s: element %d: %w", t.Name, i, err))
+			continue
+		}
+		out.Index(i).Set(elem)
+	}
+	field.Set(out)
+
+	if len(errs.Errors) == 0 {
+		return nil
+	}
+	return errs
+}
+
+func decodeMap(field reflect.Value, t reflect.StructField, strVal string) error {
+	sep := tagOrDefault(t, "sep", ",")
+	kvsep := tagOrDefault(t, "kvsep", "=")
+
+	keyType := field.Type().Key()
+	valType := field.Type().Elem()
+	out := reflect.MakeMap(field.Type())
+
+	errs := &MultiError{}
+	for _, pair := range strings.Split(strVal, sep) {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		idx := strings.Index(pair, kvsep)
+		if idx < 0 {
+			errs.add(fmt.Errorf("%s: invalid map entry %q", t.Name, pair))
+			continue
+		}
+
+		key := reflect.New(keyType).Elem()
+		if err := decodeScalar(key, t, strings.TrimSpace(pair[:idx])); err != nil {
+			errs.add(fmt.Errorf("%s: map key %q: %w", t.Name, pair[:idx], err))
+			continue
+		}
+		val := reflect.New(valType).Elem()
+		if err := decodeScalar(val, t, strings.TrimSpace(pair[idx+len(kvsep):])); err != nil {
+			errs.add(fmt.Errorf("%s: map value for %q: %w", t.Name, pair[:idx], err))
+			continue
+		}
+		out.SetMapIndex(key, val)
+	}
+	field.Set(out)
+
+	if len(errs.Errors) == 0 {
+		return nil
+	}
+	return errs
+}
+
+// decodeScalar handles the non-container cases of decodeValue, used for
+// slice/map elements.
+func decodeScalar(field reflect.Value, t reflect.StructField, strVal string) error {
+	switch field.Kind() {
+	case reflect.String, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64, reflect.Bool:
+		return decodeValue(field, t, strVal)
+	default:
+		return fmt.Errorf("unsupported element type %s for field %s", field.Type(), t.Name)
+	}
+}
+
+// formatScalarValue is decodeValue's rough inverse, stringifying a leaf
+// field's current value for Explain/Dump. Slices and maps are rendered
+// using the same "," / "=" separators decodeSlice/decodeMap split on.
+func formatScalarValue(v reflect.Value) string {
+	switch {
+	case v.Type() == durationType:
+		return time.Duration(v.Int()).String()
+	case v.Type() == timeType:
+		return v.Interface().(time.Time).Format(time.RFC3339)
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		return v.String()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(v.Uint(), 10)
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'g', -1, 64)
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool())
+	case reflect.Slice:
+		parts := make([]string, v.Len())
+		for i := 0; i < v.Len(); i++ {
+			parts[i] = formatScalarValue(v.Index(i))
+		}
+		return strings.Join(parts, ",")
+	case reflect.Map:
+		parts := make([]string, 0, v.Len())
+		for _, key := range v.MapKeys() {
+			parts = append(parts, formatScalarValue(key)+"="+formatScalarValue(v.MapIndex(key)))
+		}
+		sort.Strings(parts)
+		return strings.Join(parts, ",")
+	case reflect.Ptr:
+		if v.IsNil() {
+			return ""
+		}
+		return formatScalarValue(v.Elem())
+	default:
+		return fmt.Sprintf("%v", v.Interface())
+	}
+}
@@ -0,0 +1,274 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type reflectTestDB struct {
+	Host string `env:"HOST" def:"localhost"`
+	Port int    `env:"PORT" def:"5432"`
+}
+
+type reflectTestApp struct {
+	Name string         `env:"NAME" def:"app"`
+	DB   reflectTestDB  `env:"DB"`
+	Log  *reflectTestDB `env:"LOG"`
+}
+
+func TestChildEnvPrefix(t *testing.T) {
+	typ := reflect.TypeOf(reflectTestApp{})
+	dbField, ok := typ.FieldByName("DB")
+	if !ok {
+		t.Fatal("no DB field on reflectTestApp")
+	}
+
+	cases := []struct {
+		name   string
+		prefix string
+		want   string
+	}{
+		{"empty prefix", "", "DB"},
+		{"with prefix", "APP", "APP_DB"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := childEnvPrefix(dbField, c.prefix); got != c.want {
+				t.Errorf("childEnvPrefix(DB, %q) = %q, want %q", c.prefix, got, c.want)
+			}
+		})
+	}
+}
+
+// TestSetFromEnvOrDefaultPrefixedTracked_NestedPrefix checks that a nested
+// struct's own `env` tag is folded into the dotted env prefix seen by its
+// children, composing with a caller-supplied Options.EnvPrefix.
+func TestSetFromEnvOrDefaultPrefixedTracked_NestedPrefix(t *testing.T) {
+	os.Setenv("APP_DB_HOST", "nested-host")
+	os.Setenv("APP_DB_PORT", "6543")
+	defer os.Unsetenv("APP_DB_HOST")
+	defer os.Unsetenv("APP_DB_PORT")
+
+	var app reflectTestApp
+	if err := setFromEnvOrDefaultPrefixedTracked(&app, "APP", nil); err != nil {
+		t.Fatalf("setFromEnvOrDefaultPrefixedTracked: %v", err)
+	}
+
+	if app.DB.Host != "nested-host" {
+		t.Errorf("DB.Host = %q, want %q", app.DB.Host, "nested-host")
+	}
+	if app.DB.Port != 6543 {
+		t.Errorf("DB.Port = %d, want %d", app.DB.Port, 6543)
+	}
+	if app.Name != "app" {
+		t.Errorf("Name = %q, want default %q", app.Name, "app")
+	}
+}
+
+// TestSetFromEnvOrDefaultPrefixedTracked_PointerNested ensures a nil
+// pointer-to-struct field is allocated and its children are reached with
+// the same prefix derivation as a plain nested struct.
+func TestSetFromEnvOrDefaultPrefixedTracked_PointerNested(t *testing.T) {
+	os.Setenv("LOG_HOST", "log-host")
+	defer os.Unsetenv("LOG_HOST")
+
+	var app reflectTestApp
+	if err := setFromEnvOrDefaultPrefixedTracked(&app, "", nil); err != nil {
+		t.Fatalf("setFromEnvOrDefaultPrefixedTracked: %v", err)
+	}
+
+	if app.Log == nil {
+		t.Fatal("Log was not allocated")
+	}
+	if app.Log.Host != "log-host" {
+		t.Errorf("Log.Host = %q, want %q", app.Log.Host, "log-host")
+	}
+}
+
+// TestSetFromEnvOrDefaultPrefixedTracked_DecodeError checks that an invalid
+// value is reported through the returned error instead of being silently
+// swallowed. The field itself is left untouched by the failed env phase, so
+// the subsequent default phase still fills it in from `def`.
+func TestSetFromEnvOrDefaultPrefixedTracked_DecodeError(t *testing.T) {
+	os.Setenv("PORT", "not-a-number")
+	defer os.Unsetenv("PORT")
+
+	var db reflectTestDB
+	err := setFromEnvOrDefaultPrefixedTracked(&db, "", nil)
+	if err == nil {
+		t.Fatal("expected an error for an unparsable PORT, got nil")
+	}
+	if db.Port != 5432 {
+		t.Errorf("Port = %d, want def fallback %d after the env decode failed", db.Port, 5432)
+	}
+}
+
+// reflectTestLevel is a custom Decoder-implementing type, standing in for
+// the log-level/IP-list style types the `remote`/envconfig rewrite was
+// meant to support without editing reflect.go.
+type reflectTestLevel int
+
+func (l *reflectTestLevel) Decode(s string) error {
+	switch s {
+	case "debug":
+		*l = 0
+	case "info":
+		*l = 1
+	default:
+		return fmt.Errorf("unknown level %q", s)
+	}
+	return nil
+}
+
+// reflectTestCSV implements encoding.TextUnmarshaler, taking priority over
+// the built-in kind switch the same way reflectTestLevel's Decoder does.
+type reflectTestCSV []string
+
+func (c *reflectTestCSV) UnmarshalText(text []byte) error {
+	*c = reflectTestCSV{"unmarshaled:" + string(text)}
+	return nil
+}
+
+type reflectTestContainers struct {
+	Tags    []string          `env:"TAGS"`
+	Ports   []int             `env:"PORTS"`
+	Labels  map[string]string `env:"LABELS"`
+	Timeout time.Duration     `env:"TIMEOUT"`
+	Start   time.Time         `env:"START"`
+	Level   reflectTestLevel  `env:"LEVEL"`
+	CSV     reflectTestCSV    `env:"CSV"`
+}
+
+// TestSetFromEnvOrDefaultPrefixedTracked_Containers covers []string/[]int/
+// map[string]string decoding via the default ","/"=" separators.
+func TestSetFromEnvOrDefaultPrefixedTracked_Containers(t *testing.T) {
+	os.Setenv("TAGS", "a, b ,c")
+	os.Setenv("PORTS", "80,443")
+	os.Setenv("LABELS", "env=prod, region=us")
+	defer os.Unsetenv("TAGS")
+	defer os.Unsetenv("PORTS")
+	defer os.Unsetenv("LABELS")
+
+	var c reflectTestContainers
+	if err := setFromEnvOrDefaultPrefixedTracked(&c, "", nil); err != nil {
+		t.Fatalf("setFromEnvOrDefaultPrefixedTracked: %v", err)
+	}
+
+	if want := []string{"a", "b", "c"}; !reflect.DeepEqual(c.Tags, want) {
+		t.Errorf("Tags = %v, want %v", c.Tags, want)
+	}
+	if want := []int{80, 443}; !reflect.DeepEqual(c.Ports, want) {
+		t.Errorf("Ports = %v, want %v", c.Ports, want)
+	}
+	if want := map[string]string{"env": "prod", "region": "us"}; !reflect.DeepEqual(c.Labels, want) {
+		t.Errorf("Labels = %v, want %v", c.Labels, want)
+	}
+}
+
+// TestSetFromEnvOrDefaultPrefixedTracked_CustomSeparators checks the
+// sep/kvsep tag overrides on a slice and a map field.
+func TestSetFromEnvOrDefaultPrefixedTracked_CustomSeparators(t *testing.T) {
+	type st struct {
+		Tags   []string          `env:"TAGS" sep:"|"`
+		Labels map[string]string `env:"LABELS" sep:";" kvsep:":"`
+	}
+
+	os.Setenv("TAGS", "a|b|c")
+	os.Setenv("LABELS", "env:prod;region:us")
+	defer os.Unsetenv("TAGS")
+	defer os.Unsetenv("LABELS")
+
+	var v st
+	if err := setFromEnvOrDefaultPrefixedTracked(&v, "", nil); err != nil {
+		t.Fatalf("setFromEnvOrDefaultPrefixedTracked: %v", err)
+	}
+
+	if want := []string{"a", "b", "c"}; !reflect.DeepEqual(v.Tags, want) {
+		t.Errorf("Tags = %v, want %v", v.Tags, want)
+	}
+	if want := map[string]string{"env": "prod", "region": "us"}; !reflect.DeepEqual(v.Labels, want) {
+		t.Errorf("Labels = %v, want %v", v.Labels, want)
+	}
+}
+
+// TestSetFromEnvOrDefaultPrefixedTracked_MalformedContainerEntries checks
+// that a bad slice element and a map entry missing its kvsep are both
+// reported through the returned *MultiError instead of panicking or being
+// silently dropped, while well-formed entries alongside them still decode.
+func TestSetFromEnvOrDefaultPrefixedTracked_MalformedContainerEntries(t *testing.T) {
+	type st struct {
+		Ports  []int             `env:"PORTS"`
+		Labels map[string]string `env:"LABELS"`
+	}
+
+	os.Setenv("PORTS", "80,not-a-port")
+	os.Setenv("LABELS", "env=prod,broken-entry")
+	defer os.Unsetenv("PORTS")
+	defer os.Unsetenv("LABELS")
+
+	var v st
+	err := setFromEnvOrDefaultPrefixedTracked(&v, "", nil)
+	if err == nil {
+		t.Fatal("expected an error for malformed PORTS/LABELS entries, got nil")
+	}
+
+	if want := []int{80, 0}; !reflect.DeepEqual(v.Ports, want) {
+		t.Errorf("Ports = %v, want %v (bad element left zero-valued)", v.Ports, want)
+	}
+	if v.Labels["env"] != "prod" {
+		t.Errorf(`Labels["env"] = %q, want %q`, v.Labels["env"], "prod")
+	}
+}
+
+// TestSetFromEnvOrDefaultPrefixedTracked_DurationAndTime covers
+// time.Duration (ParseDuration) and time.Time (RFC3339) decoding.
+func TestSetFromEnvOrDefaultPrefixedTracked_DurationAndTime(t *testing.T) {
+	type st struct {
+		Timeout time.Duration `env:"TIMEOUT"`
+		Start   time.Time     `env:"START"`
+	}
+
+	os.Setenv("TIMEOUT", "1500ms")
+	os.Setenv("START", "2024-01-02T15:04:05Z")
+	defer os.Unsetenv("TIMEOUT")
+	defer os.Unsetenv("START")
+
+	var v st
+	if err := setFromEnvOrDefaultPrefixedTracked(&v, "", nil); err != nil {
+		t.Fatalf("setFromEnvOrDefaultPrefixedTracked: %v", err)
+	}
+
+	if v.Timeout != 1500*time.Millisecond {
+		t.Errorf("Timeout = %v, want %v", v.Timeout, 1500*time.Millisecond)
+	}
+
+	wantStart, _ := time.Parse(time.RFC3339, "2024-01-02T15:04:05Z")
+	if !v.Start.Equal(wantStart) {
+		t.Errorf("Start = %v, want %v", v.Start, wantStart)
+	}
+}
+
+// TestSetFromEnvOrDefaultPrefixedTracked_DecoderAndTextUnmarshaler checks
+// that a field implementing Decoder or encoding.TextUnmarshaler is decoded
+// through that interface rather than the built-in kind switch.
+func TestSetFromEnvOrDefaultPrefixedTracked_DecoderAndTextUnmarshaler(t *testing.T) {
+	os.Setenv("LEVEL", "info")
+	os.Setenv("CSV", "a,b,c")
+	defer os.Unsetenv("LEVEL")
+	defer os.Unsetenv("CSV")
+
+	var c reflectTestContainers
+	if err := setFromEnvOrDefaultPrefixedTracked(&c, "", nil); err != nil {
+		t.Fatalf("setFromEnvOrDefaultPrefixedTracked: %v", err)
+	}
+
+	if c.Level != 1 {
+		t.Errorf("Level = %d, want %d (decoded via Decoder)", c.Level, 1)
+	}
+	if want := (reflectTestCSV{"unmarshaled:a,b,c"}); !reflect.DeepEqual(c.CSV, want) {
+		t.Errorf("CSV = %v, want %v (decoded via TextUnmarshaler)", c.CSV, want)
+	}
+}
@@ -0,0 +1,334 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strings"
+)
+
+// Options controls the sources ParseWithOptions loads from and the order
+// they are merged in. The effective precedence, from lowest to highest, is:
+//
+//	def tag (hard-coded default) -> Files (in order) -> DotEnvFiles (in order) -> remote tag (SetRemoteProvider) -> process env -> Overrides
+//
+// i.e. later sources win over earlier ones. Explain reports which of these
+// actually won for each field.
+type Options struct {
+	// Files is a list of config files merged in order. The format is
+	// selected from the extension: .json, .yaml/.yml or .toml.
+	Files []string
+
+	// DotEnvFiles is a list of .env files merged in order, after Files.
+	// Keys are matched against each field's `env` tag.
+	DotEnvFiles []string
+
+	// EnvPrefix, when set, is prepended to every `env` tag before it is
+	// looked up, e.g. EnvPrefix "APP_" turns `env:"DB_HOST"` into
+	// `APP_DB_HOST`.
+	EnvPrefix string
+
+	// Overrides is applied last and always wins. Keys are matched against
+	// each field's `env` tag, same as process environment variables.
+	Overrides map[string]string
+
+	// Strict turns the legacy fmt.Println-and-continue error handling into
+	// returned errors: a source file that fails to load or parse, and a
+	// field whose value fails to decode (bad duration, bad int, unknown
+	// map entry, ...), all become entries in the *MultiError returned by
+	// ParseWithOptions instead of being logged and skipped.
+	Strict bool
+}
+
+// ParseWithOptions loads st from the chain of sources described by opts,
+// following the precedence documented on Options, applies environment
+// variables and hard-coded defaults exactly like Parse does, then validates
+// the result against every `required`/`validate` tag. All failures — source
+// load errors and per-field decode errors (bad duration, bad int, unknown
+// map entry, ...) when opts.Strict is set, and every validation failure
+// regardless of Strict — are collected into a single *MultiError rather
+// than stopping at the first one, so a misconfigured service fails fast at
+// startup with a complete report.
+func ParseWithOptions(st interface{}, opts Options) error {
+	errs := &MultiError{}
+	rec := newRecorder(reflect.ValueOf(st).Elem())
+
+	for _, file := range opts.Files {
+		if err := loadFileInto(st, file); err != nil {
+			if opts.Strict {
+				errs.add(fmt.Errorf("config: load %s: %w", file, err))
+			} else {
+				fmt.Println(err)
+			}
+		}
+	}
+	rec.mark(reflect.ValueOf(st).Elem(), "json")
+
+	// DotEnvFiles and Overrides go through the same dotted `env`-tag
+	// scoping as a real process env var, so EnvPrefix applies identically
+	// to all three: an APP_-prefixed env var, .env entry and override key
+	// all spell the same field the same way.
+	envPrefix := strings.TrimSuffix(opts.EnvPrefix, "_")
+
+	for _, file := range opts.DotEnvFiles {
+		m, err := loadDotEnv(file)
+		if err != nil {
+			if opts.Strict {
+				errs.add(fmt.Errorf("config: load %s: %w", file, err))
+			} else {
+				fmt.Println(err)
+			}
+			continue
+		}
+		if err := applyEnvMap(st, m, envPrefix); err != nil {
+			if opts.Strict {
+				errs.add(fmt.Errorf("config: apply %s: %w", file, err))
+			} else {
+				fmt.Println(err)
+			}
+		}
+	}
+	rec.mark(reflect.ValueOf(st).Elem(), "env")
+
+	if err := setFromEnvOrDefaultPrefixedTracked(st, opts.EnvPrefix, rec); err != nil {
+		if opts.Strict {
+			errs.add(err)
+		} else {
+			fmt.Println(err)
+		}
+	}
+
+	if len(opts.Overrides) > 0 {
+		if err := applyEnvMap(st, opts.Overrides, envPrefix); err != nil {
+			if opts.Strict {
+				errs.add(fmt.Errorf("config: apply overrides: %w", err))
+			} else {
+				fmt.Println(err)
+			}
+		}
+	}
+	rec.mark(reflect.ValueOf(st).Elem(), "override")
+
+	registerOrigins(st, rec.origins)
+
+	if err := validateStruct(st, rec); err != nil {
+		errs.Errors = append(errs.Errors, err.(*MultiError).Errors...)
+	}
+
+	if len(errs.Errors) == 0 {
+		return nil
+	}
+	return errs
+}
+
+// loadFileInto reads filepath and merges it into st, dispatching on
+// extension. JSON files are merged with encoding/json directly. YAML and
+// TOML go through parseSimpleKV, which understands indentation-based
+// nesting (YAML) and `[Section]` headers (TOML) well enough to build the
+// same dotted field path ("DB.Host") applyMap matches nested structs
+// against — so two sibling nested structs with the same leaf tag name
+// (`DB.Host` vs `Cache.Host`) are kept apart instead of colliding in a flat
+// map. Anything fancier (YAML flow collections, TOML arrays of tables,
+// multi-line values) is still out of scope.
+func loadFileInto(st interface{}, filepath_ string) error {
+	data, err := ioutil.ReadFile(filepath_)
+	if err != nil {
+		return err
+	}
+
+	switch strings.ToLower(filepath.Ext(filepath_)) {
+	case ".json":
+		return json.Unmarshal(data, st)
+	case ".yaml", ".yml":
+		m, err := parseSimpleKV(data, ":")
+		if err != nil {
+			return err
+		}
+		return applyMap(st, m, "json", "")
+	case ".toml":
+		m, err := parseSimpleKV(data, "=")
+		if err != nil {
+			return err
+		}
+		return applyMap(st, m, "json", "")
+	default:
+		return fmt.Errorf("config: unsupported file extension: %s", filepath_)
+	}
+}
+
+var simpleKVComment = regexp.MustCompile(`^\s*[#;]`)
+
+// parseSimpleKV parses the common top-level subset of YAML ("key: value",
+// sep ":") or TOML ("key = value", sep "=") into a flat map, keyed by the
+// dotted path applyMap recurses with ("DB.Host"), skipping blank lines and
+// #/; comments.
+//
+// For YAML, a line whose value is empty ("DB:") opens a nested block: every
+// following line indented further than it is scoped under that key, same
+// as childEnvPrefix does for env vars but "."-joined instead of "_"-joined.
+// For TOML, a "[Section]" header scopes every key=value line up to the next
+// header the same way; a dotted header ("[DB.Pool]") is used as-is as the
+// key prefix. YAML sequences/flow mappings are not supported.
+func parseSimpleKV(data []byte, sep string) (map[string]string, error) {
+	out := make(map[string]string)
+
+	type frame struct {
+		indent int
+		prefix string
+	}
+	stack := []frame{{indent: -1}}
+	section := ""
+
+	for _, raw := range strings.Split(string(data), "\n") {
+		line := strings.TrimRight(raw, "\r")
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || simpleKVComment.MatchString(trimmed) {
+			continue
+		}
+
+		if sep == "=" && strings.HasPrefix(trimmed, "[") && strings.HasSuffix(trimmed, "]") {
+			section = strings.TrimSpace(trimmed[1 : len(trimmed)-1])
+			continue
+		}
+
+		idx := strings.Index(trimmed, sep)
+		if idx < 0 {
+			return nil, fmt.Errorf("config: cannot parse line %q", trimmed)
+		}
+		key := strings.TrimSpace(trimmed[:idx])
+		val := strings.Trim(strings.TrimSpace(trimmed[idx+len(sep):]), `"'`)
+
+		if section != "" {
+			key = joinPath(section, key)
+		}
+
+		if sep == ":" {
+			indent := len(line) - len(strings.TrimLeft(line, " "))
+			for len(stack) > 1 && stack[len(stack)-1].indent >= indent {
+				stack = stack[:len(stack)-1]
+			}
+			if prefix := stack[len(stack)-1].prefix; prefix != "" {
+				key = joinPath(prefix, key)
+			}
+
+			if val == "" {
+				// "DB:" with nothing after the colon: a nested block opener,
+				// not a field whose value happens to be empty.
+				stack = append(stack, frame{indent: indent, prefix: key})
+				continue
+			}
+		}
+
+		out[key] = val
+	}
+
+	return out, nil
+}
+
+// applyMap sets each field of st whose `tagName` tag matches a key in m
+// under path (the dotted prefix contributed by enclosing nested structs, in
+// the same "." format parseSimpleKV builds), recursing into nested struct
+// fields using their own tagName tag as the next path segment. Returns a
+// *MultiError collecting every field's decode failure, or nil.
+func applyMap(st interface{}, m map[string]string, tagName, path string) error {
+	values := reflect.ValueOf(st).Elem()
+	types := values.Type()
+	errs := &MultiError{}
+
+	for i := 0; i < types.NumField(); i++ {
+		t := types.Field(i)
+		v := values.Field(i)
+
+		key := t.Tag.Get(tagName)
+		if key == "" || key == "-" {
+			key = t.Name
+		}
+		fieldPath := joinPath(path, key)
+
+		if isNestedStruct(v) {
+			var err error
+			if v.Kind() == reflect.Ptr {
+				if v.IsNil() {
+					v.Set(reflect.New(v.Type().Elem()))
+				}
+				err = applyMap(v.Interface(), m, tagName, fieldPath)
+			} else {
+				err = applyMap(v.Addr().Interface(), m, tagName, fieldPath)
+			}
+			if err != nil {
+				errs.Errors = append(errs.Errors, err.(*MultiError).Errors...)
+			}
+			continue
+		}
+
+		if val, ok := m[fieldPath]; ok && val != "" {
+			if err := decodeValue(v, t, val); err != nil {
+				errs.add(fmt.Errorf("%s: %w", t.Name, err))
+			}
+		}
+	}
+
+	if len(errs.Errors) == 0 {
+		return nil
+	}
+	return errs
+}
+
+// applyEnvMap sets each field of st from m using the same dotted `env`-tag
+// scoping as setFromEnvOrDefaultPrefixedTracked/applyEnv, so .env files and Overrides
+// compose with nested structs exactly like real environment variables do.
+// Returns a *MultiError collecting every field's decode failure, or nil.
+func applyEnvMap(st interface{}, m map[string]string, prefix string) error {
+	values := reflect.ValueOf(st).Elem()
+	types := values.Type()
+	errs := &MultiError{}
+
+	for i := 0; i < types.NumField(); i++ {
+		t := types.Field(i)
+		v := values.Field(i)
+
+		if isNestedStruct(v) {
+			childPrefix := childEnvPrefix(t, prefix)
+			var err error
+			if v.Kind() == reflect.Ptr {
+				if v.IsNil() {
+					v.Set(reflect.New(v.Type().Elem()))
+				}
+				err = applyEnvMap(v.Interface(), m, childPrefix)
+			} else {
+				err = applyEnvMap(v.Addr().Interface(), m, childPrefix)
+			}
+			if err != nil {
+				errs.Errors = append(errs.Errors, err.(*MultiError).Errors...)
+			}
+			continue
+		}
+
+		envKey := t.Tag.Get("env")
+		if envKey == "-" {
+			envKey = ""
+		} else if envKey == "" {
+			envKey = strings.ToUpper(t.Name)
+		}
+		if envKey == "" {
+			continue
+		}
+		if prefix != "" {
+			envKey = prefix + "_" + envKey
+		}
+
+		if val, ok := m[envKey]; ok && val != "" {
+			if err := decodeValue(v, t, val); err != nil {
+				errs.add(fmt.Errorf("%s: %w", t.Name, err))
+			}
+		}
+	}
+
+	if len(errs.Errors) == 0 {
+		return nil
+	}
+	return errs
+}
@@ -0,0 +1,94 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+type sourcesTestLeaf struct {
+	Host string `json:"Host"`
+}
+
+type sourcesTestConfig struct {
+	DB    sourcesTestLeaf `json:"DB"`
+	Cache sourcesTestLeaf `json:"Cache"`
+}
+
+func writeSourcesTestFile(t *testing.T, name, contents string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(contents), 0o644); err != nil {
+		t.Fatalf("os.WriteFile: %v", err)
+	}
+	return path
+}
+
+// TestLoadFileInto_YAMLNestedSections covers the chunk0-1 fix: two sibling
+// nested structs sharing a leaf tag name (DB.Host and Cache.Host) must not
+// collide into the same flat key.
+func TestLoadFileInto_YAMLNestedSections(t *testing.T) {
+	path := writeSourcesTestFile(t, "config.yaml", `
+DB:
+  Host: db-host
+Cache:
+  Host: cache-host
+`)
+
+	var cfg sourcesTestConfig
+	if err := loadFileInto(&cfg, path); err != nil {
+		t.Fatalf("loadFileInto: %v", err)
+	}
+
+	if cfg.DB.Host != "db-host" {
+		t.Errorf("DB.Host = %q, want %q", cfg.DB.Host, "db-host")
+	}
+	if cfg.Cache.Host != "cache-host" {
+		t.Errorf("Cache.Host = %q, want %q", cfg.Cache.Host, "cache-host")
+	}
+}
+
+// TestLoadFileInto_TOMLNestedSections is the TOML analog of
+// TestLoadFileInto_YAMLNestedSections, using [Section] headers instead of
+// indentation to scope each nested struct's keys.
+func TestLoadFileInto_TOMLNestedSections(t *testing.T) {
+	path := writeSourcesTestFile(t, "config.toml", `
+[DB]
+Host = db-host
+
+[Cache]
+Host = cache-host
+`)
+
+	var cfg sourcesTestConfig
+	if err := loadFileInto(&cfg, path); err != nil {
+		t.Fatalf("loadFileInto: %v", err)
+	}
+
+	if cfg.DB.Host != "db-host" {
+		t.Errorf("DB.Host = %q, want %q", cfg.DB.Host, "db-host")
+	}
+	if cfg.Cache.Host != "cache-host" {
+		t.Errorf("Cache.Host = %q, want %q", cfg.Cache.Host, "cache-host")
+	}
+}
+
+// TestParseSimpleKV_YAMLIndentScoping exercises parseSimpleKV directly,
+// checking that indentation builds dotted keys rather than a flat,
+// collision-prone map.
+func TestParseSimpleKV_YAMLIndentScoping(t *testing.T) {
+	m, err := parseSimpleKV([]byte("Name: top\nDB:\n  Host: db-host\n  Port: 5432\n"), ":")
+	if err != nil {
+		t.Fatalf("parseSimpleKV: %v", err)
+	}
+
+	want := map[string]string{"Name": "top", "DB.Host": "db-host", "DB.Port": "5432"}
+	for k, v := range want {
+		if m[k] != v {
+			t.Errorf("m[%q] = %q, want %q", k, m[k], v)
+		}
+	}
+	if _, ok := m["Host"]; ok {
+		t.Errorf("m[%q] unexpectedly set; nested key leaked into the flat namespace", "Host")
+	}
+}
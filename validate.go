@@ -0,0 +1,159 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// MultiError collects every validation failure found while walking a config
+// struct, so a misconfigured service gets one complete report instead of
+// failing on the first bad field.
+type MultiError struct {
+	Errors []error
+}
+
+func (m *MultiError) Error() string {
+	msgs := make([]string, len(m.Errors))
+	for i, err := range m.Errors {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// add appends err to the list if it is non-nil.
+func (m *MultiError) add(err error) {
+	if err != nil {
+		m.Errors = append(m.Errors, err)
+	}
+}
+
+// validateStruct walks st and checks every field's `required` and
+// `validate` tags, returning a *MultiError (nil if st is valid). rec, if
+// non-nil, is consulted so that a field explicitly set to a zero or
+// negative value isn't reported as missing by `required` — see
+// rec.touched.
+func validateStruct(st interface{}, rec *recorder) error {
+	me := &MultiError{}
+	walkValidate(reflect.ValueOf(st).Elem(), "", rec, me)
+	if len(me.Errors) == 0 {
+		return nil
+	}
+	return me
+}
+
+func walkValidate(values reflect.Value, path string, rec *recorder, me *MultiError) {
+	types := values.Type()
+
+	for i := 0; i < types.NumField(); i++ {
+		t := types.Field(i)
+		v := values.Field(i)
+		fieldPath := joinPath(path, t.Name)
+
+		if isNestedStruct(v) {
+			if v.Kind() == reflect.Ptr {
+				if v.IsNil() {
+					continue
+				}
+				walkValidate(v.Elem(), fieldPath, rec, me)
+			} else {
+				walkValidate(v, fieldPath, rec, me)
+			}
+			continue
+		}
+
+		validateField(v, t, fieldPath, rec, me)
+	}
+}
+
+func joinPath(prefix, name string) string {
+	if prefix == "" {
+		return name
+	}
+	return prefix + "." + name
+}
+
+func validateField(v reflect.Value, t reflect.StructField, path string, rec *recorder, me *MultiError) {
+	// rec.touched catches a field explicitly set to its zero value (e.g.
+	// `validate:"min=-10"` set to 0), which isSet alone would wrongly read
+	// as never set.
+	if t.Tag.Get("required") == "true" && !rec.touched(path) && !isSet(v) {
+		me.add(fmt.Errorf("config: %s is required", path))
+	}
+
+	rules := t.Tag.Get("validate")
+	if rules == "" || rules == "-" {
+		return
+	}
+
+	for _, rule := range strings.Split(rules, ",") {
+		rule = strings.TrimSpace(rule)
+		switch {
+		case rule == "nonempty":
+			if !rec.touched(path) && !isSet(v) {
+				me.add(fmt.Errorf("config: %s must not be empty", path))
+			}
+		case strings.HasPrefix(rule, "min="):
+			validateBound(v, path, rule[len("min="):], me, false)
+		case strings.HasPrefix(rule, "max="):
+			validateBound(v, path, rule[len("max="):], me, true)
+		case strings.HasPrefix(rule, "oneof="):
+			validateOneOf(v, path, strings.Fields(rule[len("oneof="):]), me)
+		case rule == "":
+			// allow trailing commas
+		default:
+			me.add(fmt.Errorf("config: %s has unknown validate rule %q", path, rule))
+		}
+	}
+}
+
+func validateBound(v reflect.Value, path, boundStr string, me *MultiError, isMax bool) {
+	bound, err := strconv.ParseFloat(boundStr, 64)
+	if err != nil {
+		me.add(fmt.Errorf("config: %s has invalid validate bound %q", path, boundStr))
+		return
+	}
+
+	n, ok := numericValue(v)
+	if !ok {
+		me.add(fmt.Errorf("config: %s is not numeric, cannot apply min/max validate rule", path))
+		return
+	}
+
+	if isMax && n > bound {
+		me.add(fmt.Errorf("config: %s=%v exceeds max=%v", path, n, bound))
+	} else if !isMax && n < bound {
+		me.add(fmt.Errorf("config: %s=%v is below min=%v", path, n, bound))
+	}
+}
+
+func numericValue(v reflect.Value) (float64, bool) {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(v.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), true
+	default:
+		return 0, false
+	}
+}
+
+// validateOneOf checks v.String() against allowed, ignoring case
+// (strings.EqualFold).
+func validateOneOf(v reflect.Value, path string, allowed []string, me *MultiError) {
+	if v.Kind() != reflect.String {
+		me.add(fmt.Errorf("config: %s is not a string, cannot apply oneof validate rule", path))
+		return
+	}
+
+	val := v.String()
+	for _, a := range allowed {
+		if strings.EqualFold(val, a) {
+			return
+		}
+	}
+	me.add(fmt.Errorf("config: %s=%q is not one of %v", path, val, allowed))
+}
@@ -0,0 +1,108 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type validateTestLeaf struct {
+	Name  string `validate:"nonempty"`
+	Level int    `validate:"min=1,max=10"`
+	Mode  string `validate:"oneof=fast slow"`
+}
+
+func TestValidateStruct_Rules(t *testing.T) {
+	cases := []struct {
+		name    string
+		st      validateTestLeaf
+		wantErr []string // substrings expected somewhere in Error()
+	}{
+		{
+			name:    "valid",
+			st:      validateTestLeaf{Name: "x", Level: 5, Mode: "fast"},
+			wantErr: nil,
+		},
+		{
+			name:    "empty name",
+			st:      validateTestLeaf{Name: "", Level: 5, Mode: "fast"},
+			wantErr: []string{"Name must not be empty"},
+		},
+		{
+			name:    "below min",
+			st:      validateTestLeaf{Name: "x", Level: 0, Mode: "fast"},
+			wantErr: []string{"below min"},
+		},
+		{
+			name:    "above max",
+			st:      validateTestLeaf{Name: "x", Level: 11, Mode: "fast"},
+			wantErr: []string{"exceeds max"},
+		},
+		{
+			name:    "not one of",
+			st:      validateTestLeaf{Name: "x", Level: 5, Mode: "medium"},
+			wantErr: []string{"is not one of"},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			err := validateStruct(&c.st, nil)
+			if len(c.wantErr) == 0 {
+				if err != nil {
+					t.Fatalf("validateStruct: unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatalf("validateStruct: expected an error containing %v, got nil", c.wantErr)
+			}
+			for _, want := range c.wantErr {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("validateStruct error %q does not contain %q", err.Error(), want)
+				}
+			}
+		})
+	}
+}
+
+func TestValidateField_UnknownRule(t *testing.T) {
+	type st struct {
+		Field string `validate:"bogus"`
+	}
+	var v st
+	err := validateStruct(&v, nil)
+	if err == nil || !strings.Contains(err.Error(), "unknown validate rule") {
+		t.Fatalf("validateStruct: want unknown-rule error, got %v", err)
+	}
+}
+
+// TestValidateField_RequiredRespectsTouched covers the chunk0-3 fix: a field
+// explicitly set to a negative value by a source (tracked by rec) must not
+// be reported as missing by `required`, even though isSet alone treats any
+// non-positive number as unset.
+func TestValidateField_RequiredRespectsTouched(t *testing.T) {
+	type st struct {
+		Offset int `required:"true" validate:"min=-10"`
+	}
+
+	v := st{Offset: -5}
+	rec := newRecorder(reflect.ValueOf(st{}))
+	rec.mark(reflect.ValueOf(v), "override") // explicitly set to -5, tracked
+
+	if err := validateStruct(&v, rec); err != nil {
+		t.Fatalf("validateStruct: field explicitly set to -5 should satisfy required, got %v", err)
+	}
+}
+
+func TestValidateField_RequiredUntouchedFailsWithoutRecorder(t *testing.T) {
+	type st struct {
+		Retries int `required:"true"`
+	}
+
+	var zero st
+	err := validateStruct(&zero, nil)
+	if err == nil || !strings.Contains(err.Error(), "is required") {
+		t.Fatalf("validateStruct: want required error for an untracked zero value, got %v", err)
+	}
+}
@@ -0,0 +1,307 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// Event describes a config reload: the dotted paths (same format as
+// MultiError's field paths) of every field whose value changed.
+type Event struct {
+	ChangedFields []string
+}
+
+// Watcher re-parses a JSON config file on fsnotify events (or on demand via
+// Reload, for SIGHUP-driven reloads), atomically swaps the new values into
+// the struct st points at, and fans the change out to every Subscribe'd
+// channel. This lets a long-running Leaf/Beego game server tune
+// SlowOpThresholdMs, MaxConnNum and friends without a restart, which the
+// one-shot Parse cannot do.
+//
+// If a Provider is registered via SetRemoteProvider, NewWatcher also starts
+// a Watch on every `remote`-tagged key reachable from st: a fleet operator
+// can flip a field like RunMode by writing one key, and every watching
+// process reloads on its own without an fsnotify event ever firing.
+//
+// Callers reading fields of the watched struct from another goroutine
+// should wrap the read in RLock/RUnlock to avoid tearing against a
+// concurrent Reload.
+type Watcher struct {
+	filepath string
+	st       interface{}
+
+	mu sync.RWMutex
+
+	fsw  *fsnotify.Watcher
+	done chan struct{}
+
+	subsMu sync.Mutex
+	subs   []chan Event
+}
+
+// NewWatcher parses filepath into st exactly like Parse, then starts
+// watching filepath for changes. st must be a pointer to a struct.
+func NewWatcher(filepath string, st interface{}) (*Watcher, error) {
+	if reflect.ValueOf(st).Kind() != reflect.Ptr {
+		return nil, fmt.Errorf("config: NewWatcher: st must be a pointer, got %T", st)
+	}
+
+	if err := Parse(filepath, st); err != nil {
+		return nil, err
+	}
+
+	fsw, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+	if err := fsw.Add(filepath); err != nil {
+		fsw.Close()
+		return nil, err
+	}
+
+	w := &Watcher{
+		filepath: filepath,
+		st:       st,
+		fsw:      fsw,
+		done:     make(chan struct{}),
+	}
+
+	go w.watchLoop()
+	w.watchRemoteKeys()
+
+	return w, nil
+}
+
+// watchRemoteKeys starts one goroutine per distinct `remote`-tagged key
+// reachable from st, each consuming the registered Provider's Watch
+// channel for that key and triggering a Reload whenever it fires. A no-op
+// if no Provider is registered or st has no `remote` tags.
+func (w *Watcher) watchRemoteKeys() {
+	provider := getRemoteProvider()
+	if provider == nil {
+		return
+	}
+
+	seen := make(map[string]bool)
+	for _, key := range collectRemoteKeys(reflect.ValueOf(w.st).Elem(), "") {
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		ch, err := provider.Watch(key)
+		if err != nil {
+			fmt.Println(err)
+			continue
+		}
+		go w.watchRemoteChan(ch)
+	}
+}
+
+// watchRemoteChan triggers a Reload every time ch fires, until ch is
+// closed or the Watcher itself is Closed.
+func (w *Watcher) watchRemoteChan(ch <-chan string) {
+	for {
+		select {
+		case _, ok := <-ch:
+			if !ok {
+				return
+			}
+			if err := w.Reload(); err != nil {
+				fmt.Println(err)
+			}
+		case <-w.done:
+			return
+		}
+	}
+}
+
+// collectRemoteKeys returns the `remote` tag value of every leaf field
+// reachable from values, recursing into nested structs the same way
+// collectSecretPaths does.
+func collectRemoteKeys(values reflect.Value, path string) map[string]string {
+	out := make(map[string]string)
+
+	types := values.Type()
+	for i := 0; i < types.NumField(); i++ {
+		t := types.Field(i)
+		v := values.Field(i)
+		fieldPath := joinPath(path, t.Name)
+
+		if isNestedStruct(v) {
+			if v.Kind() == reflect.Ptr {
+				if v.IsNil() {
+					continue
+				}
+				for p, key := range collectRemoteKeys(v.Elem(), fieldPath) {
+					out[p] = key
+				}
+			} else {
+				for p, key := range collectRemoteKeys(v, fieldPath) {
+					out[p] = key
+				}
+			}
+			continue
+		}
+
+		if key := t.Tag.Get("remote"); key != "" && key != "-" {
+			out[fieldPath] = key
+		}
+	}
+
+	return out
+}
+
+func (w *Watcher) watchLoop() {
+	for {
+		select {
+		case event, ok := <-w.fsw.Events:
+			if !ok {
+				return
+			}
+			if event.Op&(fsnotify.Write|fsnotify.Create) != 0 {
+				if err := w.Reload(); err != nil {
+					fmt.Println(err)
+				}
+			}
+		case err, ok := <-w.fsw.Errors:
+			if !ok {
+				return
+			}
+			fmt.Println(err)
+		case <-w.done:
+			return
+		}
+	}
+}
+
+// Reload re-parses filepath into a fresh copy of st's type, diffs it
+// against the live values field by field, and — if anything changed —
+// swaps it in under the write lock and notifies every subscriber. Safe to
+// call directly for a SIGHUP-driven reload in addition to the automatic
+// fsnotify-triggered ones.
+func (w *Watcher) Reload() error {
+	newSt := reflect.New(reflect.TypeOf(w.st).Elem()).Interface()
+	if err := Parse(w.filepath, newSt); err != nil {
+		return err
+	}
+
+	w.mu.RLock()
+	changed := diffFields(reflect.ValueOf(w.st).Elem(), reflect.ValueOf(newSt).Elem(), "")
+	w.mu.RUnlock()
+
+	if len(changed) == 0 {
+		return nil
+	}
+
+	w.mu.Lock()
+	reflect.ValueOf(w.st).Elem().Set(reflect.ValueOf(newSt).Elem())
+	w.mu.Unlock()
+
+	// newSt's origins (just computed by Parse) describe w.st's new values
+	// just as accurately, since we copied them field-for-field above —
+	// transfer them over so Explain(w.st) reflects the reload instead of
+	// being stuck on whatever NewWatcher's initial Parse recorded.
+	registerOrigins(w.st, loadOrigins(newSt))
+
+	w.broadcast(Event{ChangedFields: changed})
+
+	return nil
+}
+
+// Subscribe returns a channel that receives an Event after every reload
+// that actually changes a field. The channel is buffered by one and
+// notifications are sent non-blocking, so a slow subscriber only misses
+// intermediate events rather than stalling reloads.
+func (w *Watcher) Subscribe() <-chan Event {
+	ch := make(chan Event, 1)
+
+	w.subsMu.Lock()
+	w.subs = append(w.subs, ch)
+	w.subsMu.Unlock()
+
+	return ch
+}
+
+func (w *Watcher) broadcast(ev Event) {
+	w.subsMu.Lock()
+	defer w.subsMu.Unlock()
+
+	for _, ch := range w.subs {
+		select {
+		case ch <- ev:
+		default:
+		}
+	}
+}
+
+// RLock/RUnlock guard a read of the watched struct against a concurrent
+// Reload swap; Lock/Unlock are exposed for symmetry but should rarely be
+// needed since Reload itself holds the write lock only while swapping.
+func (w *Watcher) RLock()   { w.mu.RLock() }
+func (w *Watcher) RUnlock() { w.mu.RUnlock() }
+func (w *Watcher) Lock()    { w.mu.Lock() }
+func (w *Watcher) Unlock()  { w.mu.Unlock() }
+
+// Close stops the watch loop and releases the underlying fsnotify watcher.
+func (w *Watcher) Close() error {
+	close(w.done)
+	return w.fsw.Close()
+}
+
+// diffFields recurses through oldVal/newVal (same type, positionally
+// aligned) and returns the dotted path of every leaf field that differs,
+// using the same nested-struct rules and path format as validateStruct.
+func diffFields(oldVal, newVal reflect.Value, path string) []string {
+	leaves := diffLeaves(oldVal, newVal, path)
+	out := make([]string, len(leaves))
+	for i, leaf := range leaves {
+		out[i] = leaf.path
+	}
+	return out
+}
+
+// leafDiff is a changed field's path together with its new value, used by
+// diffFields (path only) and the origin recorder (path + value).
+type leafDiff struct {
+	path  string
+	value reflect.Value
+}
+
+// diffLeaves is diffFields, also keeping the new value of each changed leaf.
+func diffLeaves(oldVal, newVal reflect.Value, path string) []leafDiff {
+	var out []leafDiff
+
+	types := oldVal.Type()
+	for i := 0; i < types.NumField(); i++ {
+		t := types.Field(i)
+		ov := oldVal.Field(i)
+		nv := newVal.Field(i)
+		fieldPath := joinPath(path, t.Name)
+
+		if isNestedStruct(ov) {
+			if ov.Kind() == reflect.Ptr {
+				if ov.IsNil() != nv.IsNil() {
+					out = append(out, leafDiff{fieldPath, nv})
+					continue
+				}
+				if ov.IsNil() {
+					continue
+				}
+				out = append(out, diffLeaves(ov.Elem(), nv.Elem(), fieldPath)...)
+			} else {
+				out = append(out, diffLeaves(ov, nv, fieldPath)...)
+			}
+			continue
+		}
+
+		if !reflect.DeepEqual(ov.Interface(), nv.Interface()) {
+			out = append(out, leafDiff{fieldPath, nv})
+		}
+	}
+
+	return out
+}
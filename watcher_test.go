@@ -0,0 +1,231 @@
+package config
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type watcherTestConfig struct {
+	ListenPort int    `json:"ListenPort" def:"0"`
+	AppName    string `json:"AppName"    def:"app"`
+}
+
+func writeWatcherTestConfig(t *testing.T, path string, cfg watcherTestConfig) {
+	t.Helper()
+	data, err := json.Marshal(cfg)
+	if err != nil {
+		t.Fatalf("json.Marshal: %v", err)
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatalf("os.WriteFile: %v", err)
+	}
+}
+
+// TestWatcherReload_SwapsChangedFields exercises Reload's diff/swap path
+// directly (bypassing fsnotify timing): a changed field should be copied
+// into the live struct and reported in the returned Event, while the rest
+// of the struct is left untouched.
+func TestWatcherReload_SwapsChangedFields(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	writeWatcherTestConfig(t, path, watcherTestConfig{ListenPort: 8080, AppName: "svc"})
+
+	var cfg watcherTestConfig
+	w, err := NewWatcher(path, &cfg)
+	if err != nil {
+		t.Fatalf("NewWatcher: %v", err)
+	}
+	defer w.Close()
+
+	if cfg.ListenPort != 8080 || cfg.AppName != "svc" {
+		t.Fatalf("initial parse: got %+v", cfg)
+	}
+
+	sub := w.Subscribe()
+
+	writeWatcherTestConfig(t, path, watcherTestConfig{ListenPort: 9090, AppName: "svc"})
+	if err := w.Reload(); err != nil {
+		t.Fatalf("Reload: %v", err)
+	}
+
+	w.RLock()
+	got := cfg
+	w.RUnlock()
+
+	if got.ListenPort != 9090 {
+		t.Errorf("ListenPort = %d, want %d", got.ListenPort, 9090)
+	}
+	if got.AppName != "svc" {
+		t.Errorf("AppName = %q, want unchanged %q", got.AppName, "svc")
+	}
+
+	select {
+	case ev := <-sub:
+		if len(ev.ChangedFields) != 1 || ev.ChangedFields[0] != "ListenPort" {
+			t.Errorf("ChangedFields = %v, want [ListenPort]", ev.ChangedFields)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Reload did not broadcast a changed-field event")
+	}
+}
+
+// TestWatcherReload_NoChangeSkipsBroadcast checks that a Reload which finds
+// no diff neither swaps nor notifies subscribers.
+func TestWatcherReload_NoChangeSkipsBroadcast(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	writeWatcherTestConfig(t, path, watcherTestConfig{ListenPort: 8080, AppName: "svc"})
+
+	var cfg watcherTestConfig
+	w, err := NewWatcher(path, &cfg)
+	if err != nil {
+		t.Fatalf("NewWatcher: %v", err)
+	}
+	defer w.Close()
+
+	sub := w.Subscribe()
+
+	if err := w.Reload(); err != nil {
+		t.Fatalf("Reload: %v", err)
+	}
+
+	select {
+	case ev := <-sub:
+		t.Fatalf("Reload broadcast unexpectedly with no file change: %+v", ev)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+// TestWatcherReload_ExplainReflectsChange covers the chunk0-6 fix: before
+// it, Explain(&cfg) stayed stuck on the origins NewWatcher's initial Parse
+// recorded, because Reload parsed into a throwaway newSt and only ever
+// copied its field values onto cfg, never its origins.
+func TestWatcherReload_ExplainReflectsChange(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	writeWatcherTestConfig(t, path, watcherTestConfig{ListenPort: 8080, AppName: "svc"})
+
+	var cfg watcherTestConfig
+	w, err := NewWatcher(path, &cfg)
+	if err != nil {
+		t.Fatalf("NewWatcher: %v", err)
+	}
+	defer w.Close()
+
+	writeWatcherTestConfig(t, path, watcherTestConfig{ListenPort: 9090, AppName: "svc"})
+	if err := w.Reload(); err != nil {
+		t.Fatalf("Reload: %v", err)
+	}
+
+	origins := Explain(&cfg)
+	var got *FieldOrigin
+	for i := range origins {
+		if origins[i].Path == "ListenPort" {
+			got = &origins[i]
+			break
+		}
+	}
+	if got == nil {
+		t.Fatalf("Explain(&cfg) has no ListenPort entry: %+v", origins)
+	}
+	if got.Value != "9090" {
+		t.Errorf("ListenPort origin Value = %q, want %q", got.Value, "9090")
+	}
+	if got.Source != "json" {
+		t.Errorf("ListenPort origin Source = %q, want %q", got.Source, "json")
+	}
+}
+
+// watcherRemoteStubProvider is a controllable Provider whose Watch channel
+// the test can push values into on demand, unlike stubProvider's
+// closed-immediately one in provider_test.go.
+type watcherRemoteStubProvider struct {
+	values map[string]string
+	watch  chan string
+}
+
+func (p *watcherRemoteStubProvider) Get(key string) (string, bool, error) {
+	v, ok := p.values[key]
+	return v, ok, nil
+}
+
+func (p *watcherRemoteStubProvider) Watch(key string) (<-chan string, error) {
+	return p.watch, nil
+}
+
+// TestNewWatcher_ReloadsOnRemoteWatchEvent covers the chunk0-5 fix: a
+// Provider.Watch event for a `remote`-tagged field must trigger a Reload on
+// its own, without waiting for an fsnotify event on the local file or a
+// manual Reload call — the "flip RunMode across a fleet by writing one
+// key" payoff the backlog item promised.
+func TestNewWatcher_ReloadsOnRemoteWatchEvent(t *testing.T) {
+	type remoteTestConfig struct {
+		RunMode string `json:"RunMode" remote:"app/run_mode" def:"dev"`
+	}
+
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte(`{}`), 0o644); err != nil {
+		t.Fatalf("os.WriteFile: %v", err)
+	}
+
+	provider := &watcherRemoteStubProvider{
+		values: map[string]string{"app/run_mode": "prod"},
+		watch:  make(chan string),
+	}
+	SetRemoteProvider(provider)
+	defer SetRemoteProvider(nil)
+
+	var cfg remoteTestConfig
+	w, err := NewWatcher(path, &cfg)
+	if err != nil {
+		t.Fatalf("NewWatcher: %v", err)
+	}
+	defer w.Close()
+
+	if cfg.RunMode != "prod" {
+		t.Fatalf("initial parse: RunMode = %q, want %q", cfg.RunMode, "prod")
+	}
+
+	sub := w.Subscribe()
+
+	provider.values["app/run_mode"] = "staging"
+	provider.watch <- "staging"
+
+	select {
+	case ev := <-sub:
+		if len(ev.ChangedFields) != 1 || ev.ChangedFields[0] != "RunMode" {
+			t.Errorf("ChangedFields = %v, want [RunMode]", ev.ChangedFields)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("remote Watch event did not trigger a Reload")
+	}
+
+	w.RLock()
+	got := cfg.RunMode
+	w.RUnlock()
+
+	if got != "staging" {
+		t.Errorf("RunMode = %q, want %q", got, "staging")
+	}
+}
+
+func TestDiffFields(t *testing.T) {
+	type nested struct {
+		Host string
+	}
+	type st struct {
+		Name   string
+		Nested nested
+	}
+
+	oldVal := st{Name: "a", Nested: nested{Host: "h1"}}
+	newVal := st{Name: "a", Nested: nested{Host: "h2"}}
+
+	got := diffFields(reflect.ValueOf(oldVal), reflect.ValueOf(newVal), "")
+	want := []string{"Nested.Host"}
+
+	if len(got) != len(want) || got[0] != want[0] {
+		t.Errorf("diffFields = %v, want %v", got, want)
+	}
+}